@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/probe-lab/hermes/host"
+	"github.com/urfave/cli/v2"
+)
+
+// ReplayCommand implements `hermes replay <wal-dir>`: it re-emits a WAL
+// directory (produced by host.WAL) to a chosen DataStream sink, so operators
+// can backfill Kinesis after an outage or migrate a captured session to a
+// different backend.
+var ReplayCommand = &cli.Command{
+	Name:      "replay",
+	Usage:     "Re-emit a WAL directory's unacknowledged trace events to a sink",
+	ArgsUsage: "<wal-dir>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sink",
+			Usage: "destination data stream: kinesis, kafka, nats, file, stdout",
+			Value: "stdout",
+		},
+		&cli.StringFlag{
+			Name:  "kinesis-stream",
+			Usage: "kinesis stream name (when --sink=kinesis)",
+		},
+		&cli.StringFlag{
+			Name:  "kinesis-region",
+			Usage: "kinesis region (when --sink=kinesis)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "kafka-brokers",
+			Usage: "kafka broker addresses (when --sink=kafka)",
+		},
+		&cli.StringFlag{
+			Name:  "kafka-topic",
+			Usage: "kafka topic (when --sink=kafka)",
+		},
+		&cli.StringFlag{
+			Name:  "nats-url",
+			Usage: "nats server url (when --sink=nats)",
+		},
+		&cli.StringFlag{
+			Name:  "nats-subject",
+			Usage: "nats subject (when --sink=nats)",
+		},
+		&cli.StringFlag{
+			Name:  "file-dir",
+			Usage: "output directory (when --sink=file)",
+		},
+	},
+	Action: replayAction,
+}
+
+func replayAction(c *cli.Context) error {
+	dir := c.Args().First()
+	if dir == "" {
+		return fmt.Errorf("replay requires a <wal-dir> argument")
+	}
+
+	dsCfg := &host.DataStreamConfig{
+		Type: host.DataStreamType(c.String("sink")),
+		Kinesis: &host.KinesisDataStreamConfig{
+			StreamName: c.String("kinesis-stream"),
+			Region:     c.String("kinesis-region"),
+		},
+		Kafka: &host.KafkaDataStreamConfig{
+			Brokers: c.StringSlice("kafka-brokers"),
+			Topic:   c.String("kafka-topic"),
+		},
+		NATS: &host.NATSDataStreamConfig{
+			URL:     c.String("nats-url"),
+			Subject: c.String("nats-subject"),
+		},
+		File: &host.FileDataStreamConfig{
+			Dir: c.String("file-dir"),
+		},
+	}
+
+	ds, err := host.NewDataStream(dsCfg)
+	if err != nil {
+		return fmt.Errorf("construct sink: %w", err)
+	}
+	defer ds.Close()
+
+	if err := host.Replay(c.Context, dir, ds); err != nil {
+		return fmt.Errorf("replay %s: %w", dir, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package host
+
+import (
+	"context"
+	"log/slog"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/peer"
+
+	"github.com/probe-lab/hermes/tele"
+)
+
+// BeaconBlockDecoder extracts the handful of fields TraceBeaconBlock needs
+// out of a gossiped beacon_block message. The actual SSZ decoding (and the
+// fork-version switch that goes with it) lives in the Ethereum-side
+// subsystem that owns the beacon chain spec, not here - Host only needs
+// somewhere to plug in once that subsystem has already decoded the message
+// for its own validation.
+type BeaconBlockDecoder interface {
+	DecodeBeaconBlock(data []byte) (slot, proposerIndex uint64, blockRoot, parentRoot [32]byte, err error)
+}
+
+// BlobSidecarDecoder is BeaconBlockDecoder's counterpart for blob_sidecar_N
+// gossip messages.
+type BlobSidecarDecoder interface {
+	DecodeBlobSidecar(data []byte) (slot, blobIndex uint64, blockRoot [32]byte, kzgCommitment [48]byte, err error)
+}
+
+// WrapBeaconBlockValidator wraps the Ethereum-side subsystem's own
+// beacon_block topic validator so that every message the validator accepts
+// is also traced via TraceBeaconBlock, in addition to (not instead of) the
+// generic DeliverMessage/ValidateMessage events the RawTracer hooks already
+// emit for that same message. The subsystem registers the result in place
+// of its own validator:
+//
+//	ps.RegisterTopicValidator(beaconBlockTopic, h.WrapBeaconBlockValidator(next, decoder))
+func (h *Host) WrapBeaconBlockValidator(next pubsub.ValidatorEx, dec BeaconBlockDecoder) pubsub.ValidatorEx {
+	return func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		res := next(ctx, from, msg)
+		if res != pubsub.ValidationAccept {
+			return res
+		}
+
+		slot, proposerIndex, blockRoot, parentRoot, err := dec.DecodeBeaconBlock(msg.GetData())
+		if err != nil {
+			slog.Warn("Failed to decode beacon_block for tracing", tele.LogAttrError(err))
+			return res
+		}
+
+		h.TraceBeaconBlock(slot, proposerIndex, blockRoot, parentRoot)
+		return res
+	}
+}
+
+// WrapBlobSidecarValidator is WrapBeaconBlockValidator's counterpart for the
+// blob_sidecar_N gossip topics; see its doc comment.
+func (h *Host) WrapBlobSidecarValidator(next pubsub.ValidatorEx, dec BlobSidecarDecoder) pubsub.ValidatorEx {
+	return func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		res := next(ctx, from, msg)
+		if res != pubsub.ValidationAccept {
+			return res
+		}
+
+		slot, blobIndex, blockRoot, kzgCommitment, err := dec.DecodeBlobSidecar(msg.GetData())
+		if err != nil {
+			slog.Warn("Failed to decode blob_sidecar for tracing", tele.LogAttrError(err))
+			return res
+		}
+
+		h.TraceBlobSidecar(slot, blobIndex, blockRoot, kzgCommitment)
+		return res
+	}
+}
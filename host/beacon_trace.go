@@ -0,0 +1,33 @@
+package host
+
+import "encoding/hex"
+
+// TraceBeaconBlock emits an EventTypeBeaconBlock event carrying a beacon
+// block's decoded SSZ fields, following the Armiarma pattern of
+// piggy-backing beacon RPC methods onto the crawler host. Call it (or, more
+// commonly, call it via WrapBeaconBlockValidator) once a beacon_block
+// message has been validated/delivered, in addition to (not instead of) the
+// generic DeliverMessage/ValidateMessage events already emitted for that
+// same message.
+func (h *Host) TraceBeaconBlock(slot, proposerIndex uint64, blockRoot, parentRoot [32]byte) {
+	h.FlushTrace(EventTypeBeaconBlock, map[string]any{
+		"Slot":          slot,
+		"ProposerIndex": proposerIndex,
+		"BlockRoot":     hex.EncodeToString(blockRoot[:]),
+		"ParentRoot":    hex.EncodeToString(parentRoot[:]),
+	})
+}
+
+// TraceBlobSidecar emits an EventTypeBlobSidecar event carrying a blob
+// sidecar's decoded SSZ fields. Call it (or, more commonly, call it via
+// WrapBlobSidecarValidator) once a blob_sidecar_N message has been
+// validated/delivered, alongside the generic DeliverMessage/ValidateMessage
+// events for that same message.
+func (h *Host) TraceBlobSidecar(slot, blobIndex uint64, blockRoot [32]byte, kzgCommitment [48]byte) {
+	h.FlushTrace(EventTypeBlobSidecar, map[string]any{
+		"Slot":          slot,
+		"BlobIndex":     blobIndex,
+		"BlockRoot":     hex.EncodeToString(blockRoot[:]),
+		"KZGCommitment": hex.EncodeToString(kzgCommitment[:]),
+	})
+}
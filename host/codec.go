@@ -0,0 +1,59 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CodecType selects the wire encoding TraceEvents are serialized with before
+// being handed to a DataStream backend.
+type CodecType string
+
+const (
+	CodecTypeJSON     CodecType = "json"
+	CodecTypeProtobuf CodecType = "protobuf"
+	CodecTypeAvro     CodecType = "avro"
+)
+
+// TraceEventCodec encodes/decodes the TraceEvent envelope to/from its wire
+// representation. DataStream backends call Encode once per event rather than
+// marshalling with encoding/json directly, so the codec can be swapped
+// without touching any backend.
+type TraceEventCodec interface {
+	Encode(evt *TraceEvent) ([]byte, error)
+	Decode(data []byte) (*TraceEvent, error)
+	Name() string
+}
+
+// NewCodec constructs the TraceEventCodec selected by typ. cfg is only
+// consulted for CodecTypeAvro, where it carries the schema registry URL.
+func NewCodec(typ CodecType, cfg *AvroCodecConfig) (TraceEventCodec, error) {
+	switch typ {
+	case "", CodecTypeJSON:
+		return JSONCodec{}, nil
+	case CodecTypeProtobuf:
+		return ProtobufCodec{}, nil
+	case CodecTypeAvro:
+		return NewAvroCodec(cfg)
+	default:
+		return nil, fmt.Errorf("unknown codec type %q", typ)
+	}
+}
+
+// JSONCodec is the original encoding/json-based wire format and remains the
+// default: it's the most debuggable and has no schema management overhead.
+type JSONCodec struct{}
+
+var _ TraceEventCodec = JSONCodec{}
+
+func (JSONCodec) Encode(evt *TraceEvent) ([]byte, error) { return json.Marshal(evt) }
+
+func (JSONCodec) Decode(data []byte) (*TraceEvent, error) {
+	var evt TraceEvent
+	if err := json.Unmarshal(data, &evt); err != nil {
+		return nil, err
+	}
+	return &evt, nil
+}
+
+func (JSONCodec) Name() string { return string(CodecTypeJSON) }
@@ -0,0 +1,454 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// confluentMagicByte is prepended (per the Confluent wire format) before the
+// 4-byte big-endian schema ID and the Avro-encoded body, so any consumer
+// pointed at the same registry can deserialize without out-of-band schema
+// sharing.
+const confluentMagicByte = 0x0
+
+// traceEventAvroSchema is the Avro equivalent of pb/trace.proto's TraceEvent:
+// the envelope fields plus a single nullable "payload" record wide enough to
+// hold every field any EventType's schema (see eventSchemas) uses. Only the
+// fields relevant to evt.Type are ever populated; the rest are left at their
+// zero value so one record can represent every typed payload without an
+// Avro union of one record type per EventType.
+const traceEventAvroSchema = `{
+  "type": "record",
+  "name": "TraceEvent",
+  "namespace": "hermes.trace.v1",
+  "fields": [
+    {"name": "version", "type": "int"},
+    {"name": "network_id", "type": "string"},
+    {"name": "host_id", "type": "string"},
+    {"name": "type", "type": "string"},
+    {"name": "peer_id", "type": "string"},
+    {"name": "timestamp_unix_nano", "type": "long"},
+    {"name": "payload", "type": ["null", {
+      "type": "record",
+      "name": "Payload",
+      "fields": [
+        {"name": "peer_id", "type": ["null", "string"], "default": null},
+        {"name": "protocol", "type": ["null", "string"], "default": null},
+        {"name": "topic", "type": ["null", "string"], "default": null},
+        {"name": "msg_id", "type": ["null", "string"], "default": null},
+        {"name": "local", "type": ["null", "boolean"], "default": null},
+        {"name": "msg_size", "type": ["null", "long"], "default": null},
+        {"name": "seq", "type": ["null", "string"], "default": null},
+        {"name": "reason", "type": ["null", "string"], "default": null},
+        {"name": "subscribe", "type": ["null", "boolean"], "default": null},
+        {"name": "msg_ids", "type": ["null", {"type": "array", "items": "string"}], "default": null},
+        {"name": "backoff", "type": ["null", "long"], "default": null},
+        {"name": "peers", "type": ["null", {"type": "array", "items": "string"}], "default": null},
+        {"name": "slot", "type": ["null", "long"], "default": null},
+        {"name": "proposer_index", "type": ["null", "long"], "default": null},
+        {"name": "block_root", "type": ["null", "string"], "default": null},
+        {"name": "parent_root", "type": ["null", "string"], "default": null},
+        {"name": "blob_index", "type": ["null", "long"], "default": null},
+        {"name": "kzg_commitment", "type": ["null", "string"], "default": null},
+        {"name": "raw_json", "type": ["null", "bytes"], "default": null}
+      ]
+    }], "default": null}
+  ]
+}`
+
+// AvroCodecConfig configures the Avro codec and its schema registry client.
+type AvroCodecConfig struct {
+	// RegistryURL is the base URL of a Confluent-compatible schema registry,
+	// e.g. http://localhost:8081.
+	RegistryURL string
+
+	// Subject is the schema registry subject the TraceEvent schema is
+	// registered under. Defaults to "hermes-trace-value".
+	Subject string
+}
+
+// avroPayload is traceEventAvroSchema's nullable "payload" record. Every
+// field is a pointer so an absent value serializes as Avro null rather than
+// a zero value indistinguishable from "actually zero".
+type avroPayload struct {
+	PeerID        *string  `avro:"peer_id"`
+	Protocol      *string  `avro:"protocol"`
+	Topic         *string  `avro:"topic"`
+	MsgID         *string  `avro:"msg_id"`
+	Local         *bool    `avro:"local"`
+	MsgSize       *int64   `avro:"msg_size"`
+	Seq           *string  `avro:"seq"`
+	Reason        *string  `avro:"reason"`
+	Subscribe     *bool    `avro:"subscribe"`
+	MsgIDs        []string `avro:"msg_ids"`
+	Backoff       *int64   `avro:"backoff"`
+	Peers         []string `avro:"peers"`
+	Slot          *int64   `avro:"slot"`
+	ProposerIndex *int64   `avro:"proposer_index"`
+	BlockRoot     *string  `avro:"block_root"`
+	ParentRoot    *string  `avro:"parent_root"`
+	BlobIndex     *int64   `avro:"blob_index"`
+	KZGCommitment *string  `avro:"kzg_commitment"`
+	// RawJSON carries the whole Payload for EventTypeRecvRPC/SendRPC/DropRPC,
+	// whose shape comes from the external newRPCMeta helper and isn't in
+	// eventSchemas - same fallback ProtobufCodec uses.
+	RawJSON []byte `avro:"raw_json"`
+}
+
+type avroTraceEventRecord struct {
+	Version           int          `avro:"version"`
+	NetworkID         string       `avro:"network_id"`
+	HostID            string       `avro:"host_id"`
+	Type              string       `avro:"type"`
+	PeerID            string       `avro:"peer_id"`
+	TimestampUnixNano int64        `avro:"timestamp_unix_nano"`
+	Payload           *avroPayload `avro:"payload"`
+}
+
+// AvroCodec encodes TraceEvents as Avro, prefixed with the Confluent
+// magic byte + schema ID so Kinesis/Kafka consumers can resolve the schema
+// from the registry instead of needing it distributed alongside Hermes.
+type AvroCodec struct {
+	schema   avro.Schema
+	registry *schemaRegistryClient
+
+	mu       sync.Mutex
+	schemaID int
+}
+
+var _ TraceEventCodec = (*AvroCodec)(nil)
+
+// NewAvroCodec constructs an Avro codec and registers traceEventAvroSchema
+// with the configured schema registry, caching the returned schema ID for
+// every subsequent Encode call.
+func NewAvroCodec(cfg *AvroCodecConfig) (*AvroCodec, error) {
+	if cfg == nil || cfg.RegistryURL == "" {
+		return nil, fmt.Errorf("avro codec requires a schema registry url")
+	}
+
+	subject := cfg.Subject
+	if subject == "" {
+		subject = "hermes-trace-value"
+	}
+
+	schema, err := avro.Parse(traceEventAvroSchema)
+	if err != nil {
+		return nil, fmt.Errorf("parse avro schema: %w", err)
+	}
+
+	registry := &schemaRegistryClient{baseURL: cfg.RegistryURL, client: &http.Client{Timeout: 10 * time.Second}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	schemaID, err := registry.register(ctx, subject, traceEventAvroSchema)
+	if err != nil {
+		return nil, fmt.Errorf("register avro schema: %w", err)
+	}
+
+	return &AvroCodec{schema: schema, registry: registry, schemaID: schemaID}, nil
+}
+
+func (c *AvroCodec) Encode(evt *TraceEvent) ([]byte, error) {
+	payload, err := encodeAvroPayload(evt)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := avro.Marshal(c.schema, avroTraceEventRecord{
+		Version:           evt.Version,
+		NetworkID:         evt.NetworkID,
+		HostID:            evt.HostID,
+		Type:              string(evt.Type),
+		PeerID:            evt.PeerID.String(),
+		TimestampUnixNano: evt.Timestamp.UnixNano(),
+		Payload:           payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal avro record: %w", err)
+	}
+
+	c.mu.Lock()
+	schemaID := c.schemaID
+	c.mu.Unlock()
+
+	var buf bytes.Buffer
+	buf.WriteByte(confluentMagicByte)
+	_ = binary.Write(&buf, binary.BigEndian, int32(schemaID))
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// encodeAvroPayload mirrors protobufEncodePayload but targets avroPayload's
+// named fields instead of positional protobuf field numbers, using the same
+// eventSchemas table so the two codecs can't silently drift apart on which
+// EventTypes carry which fields.
+func encodeAvroPayload(evt *TraceEvent) (*avroPayload, error) {
+	if _, ok := pbFallbackField(evt.Type); ok {
+		raw, err := json.Marshal(evt.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("marshal trace event payload: %w", err)
+		}
+		return &avroPayload{RawJSON: raw}, nil
+	}
+
+	schema, ok := eventSchemas[evt.Type]
+	if !ok {
+		return nil, fmt.Errorf("avro codec: no schema registered for event type %q", evt.Type)
+	}
+
+	p := payloadMap(evt.Payload)
+	out := &avroPayload{}
+
+	for _, f := range schema.fields {
+		switch f.key {
+		case "PeerID":
+			out.PeerID = avroStringField(p, f)
+		case "Protocol":
+			out.Protocol = avroStringField(p, f)
+		case "Topic":
+			out.Topic = avroStringField(p, f)
+		case "MsgID":
+			out.MsgID = avroStringField(p, f)
+		case "Local":
+			out.Local = avroBoolField(p, f)
+		case "MsgSize":
+			out.MsgSize = avroVarintField(p, f)
+		case "Seq", "SeqNo":
+			out.Seq = avroStringField(p, f)
+		case "Reason":
+			out.Reason = avroRejectReasonField(p, f)
+		case "Subscribe":
+			out.Subscribe = avroBoolField(p, f)
+		case "MsgIDs":
+			out.MsgIDs = payloadStringSlice(p, f.key)
+		case "Backoff":
+			out.Backoff = avroVarintField(p, f)
+		case "Peers":
+			out.Peers = payloadStringSlice(p, f.key)
+		case "Slot":
+			out.Slot = avroVarintField(p, f)
+		case "ProposerIndex":
+			out.ProposerIndex = avroVarintField(p, f)
+		case "BlockRoot":
+			out.BlockRoot = avroStringField(p, f)
+		case "ParentRoot":
+			out.ParentRoot = avroStringField(p, f)
+		case "BlobIndex":
+			out.BlobIndex = avroVarintField(p, f)
+		case "KZGCommitment":
+			out.KZGCommitment = avroStringField(p, f)
+		}
+	}
+
+	return out, nil
+}
+
+func avroStringField(p map[string]any, f fieldDesc) *string {
+	v := payloadString(p, f.key)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func avroBoolField(p map[string]any, f fieldDesc) *bool {
+	if !payloadBool(p, f.key) {
+		return nil
+	}
+	v := true
+	return &v
+}
+
+func avroVarintField(p map[string]any, f fieldDesc) *int64 {
+	v := payloadUint64(p, f.key)
+	if v == 0 {
+		return nil
+	}
+	n := int64(v)
+	return &n
+}
+
+func avroRejectReasonField(p map[string]any, f fieldDesc) *string {
+	r := parseRejectReason(payloadString(p, f.key))
+	if r == RejectReasonUnspecified {
+		return nil
+	}
+	s := r.String()
+	return &s
+}
+
+func (c *AvroCodec) Decode(data []byte) (*TraceEvent, error) {
+	if len(data) < 5 || data[0] != confluentMagicByte {
+		return nil, fmt.Errorf("malformed confluent-framed avro payload")
+	}
+
+	var rec avroTraceEventRecord
+	if err := avro.Unmarshal(c.schema, data[5:], &rec); err != nil {
+		return nil, fmt.Errorf("unmarshal avro record: %w", err)
+	}
+
+	id, err := peer.Decode(rec.PeerID)
+	if err != nil {
+		return nil, fmt.Errorf("decode peer id: %w", err)
+	}
+
+	evt := &TraceEvent{
+		Version:   rec.Version,
+		NetworkID: rec.NetworkID,
+		HostID:    rec.HostID,
+		Type:      EventType(rec.Type),
+		PeerID:    id,
+		Timestamp: time.Unix(0, rec.TimestampUnixNano),
+	}
+
+	if rec.Payload != nil {
+		payload, err := decodeAvroPayload(evt.Type, rec.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal trace event payload: %w", err)
+		}
+		evt.Payload = payload
+	}
+
+	return evt, nil
+}
+
+func decodeAvroPayload(t EventType, rec *avroPayload) (any, error) {
+	if _, ok := pbFallbackField(t); ok {
+		if len(rec.RawJSON) == 0 {
+			return nil, nil
+		}
+		var p any
+		if err := json.Unmarshal(rec.RawJSON, &p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	schema, ok := eventSchemas[t]
+	if !ok {
+		return nil, fmt.Errorf("no schema registered for event type %q", t)
+	}
+
+	p := make(map[string]any, len(schema.fields))
+	for _, f := range schema.fields {
+		switch f.key {
+		case "PeerID":
+			setAvroString(p, f.key, rec.PeerID)
+		case "Protocol":
+			setAvroString(p, f.key, rec.Protocol)
+		case "Topic":
+			setAvroString(p, f.key, rec.Topic)
+		case "MsgID":
+			setAvroString(p, f.key, rec.MsgID)
+		case "Local":
+			setAvroBool(p, f.key, rec.Local)
+		case "MsgSize":
+			setAvroVarint(p, f.key, rec.MsgSize)
+		case "Seq", "SeqNo":
+			setAvroString(p, f.key, rec.Seq)
+		case "Reason":
+			if rec.Reason != nil {
+				p[f.key] = *rec.Reason
+			}
+		case "Subscribe":
+			setAvroBool(p, f.key, rec.Subscribe)
+		case "MsgIDs":
+			if len(rec.MsgIDs) > 0 {
+				p[f.key] = rec.MsgIDs
+			}
+		case "Backoff":
+			setAvroVarint(p, f.key, rec.Backoff)
+		case "Peers":
+			if len(rec.Peers) > 0 {
+				p[f.key] = rec.Peers
+			}
+		case "Slot":
+			setAvroVarint(p, f.key, rec.Slot)
+		case "ProposerIndex":
+			setAvroVarint(p, f.key, rec.ProposerIndex)
+		case "BlockRoot":
+			setAvroString(p, f.key, rec.BlockRoot)
+		case "ParentRoot":
+			setAvroString(p, f.key, rec.ParentRoot)
+		case "BlobIndex":
+			setAvroVarint(p, f.key, rec.BlobIndex)
+		case "KZGCommitment":
+			setAvroString(p, f.key, rec.KZGCommitment)
+		}
+	}
+
+	return p, nil
+}
+
+func setAvroString(p map[string]any, key string, v *string) {
+	if v != nil {
+		p[key] = *v
+	}
+}
+
+func setAvroBool(p map[string]any, key string, v *bool) {
+	if v != nil {
+		p[key] = *v
+	}
+}
+
+func setAvroVarint(p map[string]any, key string, v *int64) {
+	if v != nil {
+		p[key] = uint64(*v)
+	}
+}
+
+func (c *AvroCodec) Name() string { return string(CodecTypeAvro) }
+
+// schemaRegistryClient is a minimal Confluent schema registry client: just
+// enough to register a subject's schema and get back its ID.
+type schemaRegistryClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func (c *schemaRegistryClient) register(ctx context.Context, subject, schema string) (int, error) {
+	body, err := json.Marshal(map[string]string{"schema": schema})
+	if err != nil {
+		return 0, err
+	}
+
+	url := fmt.Sprintf("%s/subjects/%s/versions", c.baseURL, subject)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("schema registry returned %d: %s", resp.StatusCode, string(b))
+	}
+
+	var respBody struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&respBody); err != nil {
+		return 0, fmt.Errorf("decode schema registry response: %w", err)
+	}
+
+	return respBody.ID, nil
+}
@@ -0,0 +1,302 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers must stay in sync with pb/trace.proto's TraceEvent message.
+const (
+	pbFieldVersion     = 1
+	pbFieldNetworkID   = 2
+	pbFieldHostID      = 3
+	pbFieldType        = 4
+	pbFieldPeerID      = 5
+	pbFieldTimestampNS = 6
+
+	pbFieldRecvRPCJSON = 23
+	pbFieldSendRPCJSON = 24
+	pbFieldDropRPCJSON = 25
+)
+
+// pbFallbackField returns the scoped raw-JSON oneof field number used for
+// EventTypeRecvRPC/SendRPC/DropRPC, whose payload shape comes from the
+// external newRPCMeta helper and isn't in eventSchemas.
+func pbFallbackField(t EventType) (int, bool) {
+	switch t {
+	case EventTypeRecvRPC:
+		return pbFieldRecvRPCJSON, true
+	case EventTypeSendRPC:
+		return pbFieldSendRPCJSON, true
+	case EventTypeDropRPC:
+		return pbFieldDropRPCJSON, true
+	default:
+		return 0, false
+	}
+}
+
+// ProtobufCodec encodes TraceEvents using the wire format described by
+// pb/trace.proto. Envelope fields (version, network/host ID, type, peer ID,
+// timestamp) are encoded as native protobuf varints/strings, and Payload is
+// encoded into the typed oneof submessage matching evt.Type (see
+// eventSchemas), rather than a generic JSON blob - the recv/send/drop RPC
+// summary events are the one exception, since their payload shape comes
+// from outside this package.
+type ProtobufCodec struct{}
+
+var _ TraceEventCodec = ProtobufCodec{}
+
+func (ProtobufCodec) Encode(evt *TraceEvent) ([]byte, error) {
+	var b []byte
+	b = protowire.AppendTag(b, pbFieldVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(evt.Version))
+
+	b = protowire.AppendTag(b, pbFieldNetworkID, protowire.BytesType)
+	b = protowire.AppendString(b, evt.NetworkID)
+
+	b = protowire.AppendTag(b, pbFieldHostID, protowire.BytesType)
+	b = protowire.AppendString(b, evt.HostID)
+
+	b = protowire.AppendTag(b, pbFieldType, protowire.BytesType)
+	b = protowire.AppendString(b, string(evt.Type))
+
+	b = protowire.AppendTag(b, pbFieldPeerID, protowire.BytesType)
+	b = protowire.AppendString(b, evt.PeerID.String())
+
+	b = protowire.AppendTag(b, pbFieldTimestampNS, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(evt.Timestamp.UnixNano()))
+
+	if field, ok := pbFallbackField(evt.Type); ok {
+		payload, err := json.Marshal(evt.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("marshal trace event payload: %w", err)
+		}
+		b = protowire.AppendTag(b, protowire.Number(field), protowire.BytesType)
+		b = protowire.AppendBytes(b, payload)
+		return b, nil
+	}
+
+	schema, ok := eventSchemas[evt.Type]
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: no schema registered for event type %q", evt.Type)
+	}
+
+	b = protowire.AppendTag(b, protowire.Number(schema.oneof), protowire.BytesType)
+	b = protowire.AppendBytes(b, protobufEncodePayload(schema, payloadMap(evt.Payload)))
+
+	return b, nil
+}
+
+// protobufEncodePayload encodes p's fields into schema's submessage, using
+// each field's 1-based position in schema.fields as its field number.
+// Zero-value fields are omitted, matching proto3 semantics.
+func protobufEncodePayload(schema eventSchema, p map[string]any) []byte {
+	var b []byte
+	for i, f := range schema.fields {
+		num := protowire.Number(i + 1)
+
+		switch f.kind {
+		case fieldString:
+			if v := payloadString(p, f.key); v != "" {
+				b = protowire.AppendTag(b, num, protowire.BytesType)
+				b = protowire.AppendString(b, v)
+			}
+		case fieldBool:
+			if payloadBool(p, f.key) {
+				b = protowire.AppendTag(b, num, protowire.VarintType)
+				b = protowire.AppendVarint(b, 1)
+			}
+		case fieldVarint:
+			if v := payloadUint64(p, f.key); v != 0 {
+				b = protowire.AppendTag(b, num, protowire.VarintType)
+				b = protowire.AppendVarint(b, v)
+			}
+		case fieldRepeatedString:
+			for _, v := range payloadStringSlice(p, f.key) {
+				b = protowire.AppendTag(b, num, protowire.BytesType)
+				b = protowire.AppendString(b, v)
+			}
+		case fieldRejectReason:
+			if v := parseRejectReason(payloadString(p, f.key)); v != RejectReasonUnspecified {
+				b = protowire.AppendTag(b, num, protowire.VarintType)
+				b = protowire.AppendVarint(b, uint64(v))
+			}
+		}
+	}
+	return b
+}
+
+func (ProtobufCodec) Decode(data []byte) (*TraceEvent, error) {
+	evt := &TraceEvent{}
+
+	var payloadField int
+	var payload []byte
+	for len(data) > 0 {
+		num, _, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		switch num {
+		case pbFieldVersion:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			evt.Version = int(v)
+			data = data[n:]
+		case pbFieldNetworkID:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			evt.NetworkID = v
+			data = data[n:]
+		case pbFieldHostID:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			evt.HostID = v
+			data = data[n:]
+		case pbFieldType:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			evt.Type = EventType(v)
+			data = data[n:]
+		case pbFieldPeerID:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			id, err := peer.Decode(v)
+			if err != nil {
+				return nil, fmt.Errorf("decode peer id: %w", err)
+			}
+			evt.PeerID = id
+			data = data[n:]
+		case pbFieldTimestampNS:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			evt.Timestamp = time.Unix(0, int64(v))
+			data = data[n:]
+		case pbFieldRecvRPCJSON, pbFieldSendRPCJSON, pbFieldDropRPCJSON:
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			payloadField, payload = int(num), v
+			data = data[n:]
+		default:
+			// One of the typed oneof submessages; defer decoding until we
+			// know evt.Type, since schema lookup is keyed on it.
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			payloadField, payload = int(num), v
+			data = data[n:]
+		}
+	}
+
+	if payload == nil {
+		return evt, nil
+	}
+
+	if payloadField == pbFieldRecvRPCJSON || payloadField == pbFieldSendRPCJSON || payloadField == pbFieldDropRPCJSON {
+		var p any
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return nil, fmt.Errorf("unmarshal trace event payload: %w", err)
+		}
+		evt.Payload = p
+		return evt, nil
+	}
+
+	schema, ok := eventSchemas[evt.Type]
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: no schema registered for event type %q", evt.Type)
+	}
+
+	p, err := protobufDecodePayload(schema, payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode trace event payload: %w", err)
+	}
+	evt.Payload = p
+
+	return evt, nil
+}
+
+func protobufDecodePayload(schema eventSchema, data []byte) (map[string]any, error) {
+	p := make(map[string]any, len(schema.fields))
+
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		idx := int(num) - 1
+		if idx < 0 || idx >= len(schema.fields) {
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+
+		f := schema.fields[idx]
+		switch f.kind {
+		case fieldString:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			p[f.key] = v
+			data = data[n:]
+		case fieldBool:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			p[f.key] = v != 0
+			data = data[n:]
+		case fieldVarint:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			p[f.key] = v
+			data = data[n:]
+		case fieldRepeatedString:
+			v, n := protowire.ConsumeString(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			existing, _ := p[f.key].([]string)
+			p[f.key] = append(existing, v)
+			data = data[n:]
+		case fieldRejectReason:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			p[f.key] = RejectReason(v).String()
+			data = data[n:]
+		}
+	}
+
+	return p, nil
+}
+
+func (ProtobufCodec) Name() string { return string(CodecTypeProtobuf) }
@@ -0,0 +1,178 @@
+package host
+
+import "fmt"
+
+// fieldKind identifies how a payload field should round-trip through a
+// typed wire format. Shared between ProtobufCodec and AvroCodec so the two
+// don't each hand-roll their own per-EventType switch.
+type fieldKind int
+
+const (
+	fieldString fieldKind = iota
+	fieldBool
+	fieldVarint
+	fieldRepeatedString
+	fieldRejectReason
+)
+
+// fieldDesc describes one field of an EventType's payload map. A field's
+// 1-based position within eventSchema.fields is its protobuf field number
+// within the oneof submessage (see pb/trace.proto, whose message field
+// order must mirror this slice's order); its key selects the matching
+// field of the Avro payload record (see encodeAvroPayload).
+type fieldDesc struct {
+	key  string
+	kind fieldKind
+}
+
+// eventSchema describes how one (or several, direction-variant) EventTypes'
+// Payload map maps onto pb/trace.proto's oneof.
+type eventSchema struct {
+	// oneof is the TraceEvent.payload oneof field number (pb/trace.proto)
+	// the fields below belong to.
+	oneof int32
+	fields []fieldDesc
+}
+
+// eventSchemas maps every typed EventType to its wire schema. EventTypeRecvRPC,
+// EventTypeSendRPC and EventTypeDropRPC are deliberately absent: their payload
+// comes from the external newRPCMeta helper, whose shape this package can't
+// see, so both codecs fall back to a raw JSON blob for those three (see
+// pb/trace.proto's recv_rpc_json/send_rpc_json/drop_rpc_json fields).
+var eventSchemas = map[EventType]eventSchema{
+	EventTypeAddPeer:    {oneof: 10, fields: []fieldDesc{{"PeerID", fieldString}, {"Protocol", fieldString}}},
+	EventTypeRemovePeer: {oneof: 11, fields: []fieldDesc{{"PeerID", fieldString}}},
+	EventTypeJoin:       {oneof: 12, fields: []fieldDesc{{"Topic", fieldString}}},
+	EventTypeLeave:      {oneof: 13, fields: []fieldDesc{{"Topic", fieldString}}},
+	EventTypeGraft:      {oneof: 14, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}}},
+	EventTypePrune:      {oneof: 15, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}}},
+	EventTypeValidateMessage: {oneof: 16, fields: []fieldDesc{
+		{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgID", fieldString},
+		{"Local", fieldBool}, {"MsgSize", fieldVarint}, {"SeqNo", fieldString},
+	}},
+	EventTypeDeliverMessage: {oneof: 17, fields: []fieldDesc{
+		{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgID", fieldString},
+		{"Local", fieldBool}, {"MsgSize", fieldVarint}, {"Seq", fieldString},
+	}},
+	EventTypeRejectMessage: {oneof: 18, fields: []fieldDesc{
+		{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgID", fieldString}, {"Reason", fieldRejectReason},
+		{"Local", fieldBool}, {"MsgSize", fieldVarint}, {"Seq", fieldString},
+	}},
+	EventTypeDuplicateMessage: {oneof: 19, fields: []fieldDesc{
+		{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgID", fieldString},
+		{"Local", fieldBool}, {"MsgSize", fieldVarint}, {"Seq", fieldString},
+	}},
+	EventTypeThrottlePeer: {oneof: 20, fields: []fieldDesc{{"PeerID", fieldString}}},
+	EventTypeUndeliverableMessage: {oneof: 21, fields: []fieldDesc{
+		{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgID", fieldString}, {"Local", fieldBool},
+	}},
+	EventTypePublishMessage: {oneof: 22, fields: []fieldDesc{{"MsgID", fieldString}, {"Topic", fieldString}}},
+
+	// Per-control-message events: the direction (recv/send/drop) already
+	// lives in TraceEvent.type, so each trio below shares one oneof case.
+	EventTypeRecvSubscribe: {oneof: 26, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Subscribe", fieldBool}}},
+	EventTypeSendSubscribe: {oneof: 26, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Subscribe", fieldBool}}},
+	EventTypeDropSubscribe: {oneof: 26, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Subscribe", fieldBool}}},
+
+	EventTypeRecvPublish: {oneof: 27, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgSize", fieldVarint}}},
+	EventTypeSendPublish: {oneof: 27, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgSize", fieldVarint}}},
+	EventTypeDropPublish: {oneof: 27, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgSize", fieldVarint}}},
+
+	EventTypeRecvIHAVE: {oneof: 28, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeSendIHAVE: {oneof: 28, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeDropIHAVE: {oneof: 28, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+
+	EventTypeRecvIWANT: {oneof: 29, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeSendIWANT: {oneof: 29, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeDropIWANT: {oneof: 29, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+
+	EventTypeRecvGraft: {oneof: 30, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}}},
+	EventTypeSendGraft: {oneof: 30, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}}},
+	EventTypeDropGraft: {oneof: 30, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}}},
+
+	EventTypeRecvPrune: {oneof: 31, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Backoff", fieldVarint}, {"Peers", fieldRepeatedString}}},
+	EventTypeSendPrune: {oneof: 31, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Backoff", fieldVarint}, {"Peers", fieldRepeatedString}}},
+	EventTypeDropPrune: {oneof: 31, fields: []fieldDesc{{"PeerID", fieldString}, {"Topic", fieldString}, {"Backoff", fieldVarint}, {"Peers", fieldRepeatedString}}},
+
+	EventTypeRecvIDontWant: {oneof: 32, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeSendIDontWant: {oneof: 32, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+	EventTypeDropIDontWant: {oneof: 32, fields: []fieldDesc{{"PeerID", fieldString}, {"MsgIDs", fieldRepeatedString}}},
+
+	EventTypeBeaconBlock: {oneof: 33, fields: []fieldDesc{
+		{"Slot", fieldVarint}, {"ProposerIndex", fieldVarint}, {"BlockRoot", fieldString}, {"ParentRoot", fieldString},
+	}},
+	EventTypeBlobSidecar: {oneof: 34, fields: []fieldDesc{
+		{"Slot", fieldVarint}, {"BlobIndex", fieldVarint}, {"BlockRoot", fieldString}, {"KZGCommitment", fieldString},
+	}},
+}
+
+// payloadMap type-asserts evt.Payload into the map[string]any every Host
+// tracer method and rpc_trace.go construct it as. A non-map (or nil)
+// Payload just yields no fields rather than an error - callers still get a
+// valid, if empty, typed submessage.
+func payloadMap(payload any) map[string]any {
+	p, _ := payload.(map[string]any)
+	return p
+}
+
+// payloadString reads key as a string, accepting anything Stringer-like
+// (e.g. peer.ID, which several Payload maps store unconverted) as well as
+// plain strings.
+func payloadString(p map[string]any, key string) string {
+	return stringify(p[key])
+}
+
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func payloadBool(p map[string]any, key string) bool {
+	v, _ := p[key].(bool)
+	return v
+}
+
+// payloadUint64 reads key as a uint64, accepting any of the concrete
+// integer/float types Payload maps use (int from len()/Size(), int64 from
+// GetBackoff(), uint64 from slot numbers, float64 after a JSON round-trip).
+func payloadUint64(p map[string]any, key string) uint64 {
+	switch v := p[key].(type) {
+	case uint64:
+		return v
+	case int64:
+		return uint64(v)
+	case int:
+		return uint64(v)
+	case int32:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case float64:
+		return uint64(v)
+	default:
+		return 0
+	}
+}
+
+func payloadStringSlice(p map[string]any, key string) []string {
+	switch v := p[key].(type) {
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, e := range v {
+			out = append(out, stringify(e))
+		}
+		return out
+	default:
+		return nil
+	}
+}
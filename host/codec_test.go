@@ -0,0 +1,226 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const testCodecPeerID = "12D3KooWAJjbRkp8FPF5i51QHT3Gn6aUbwgVKkGAT9wWzgoTLK7V"
+
+func testCodecEvent(t *testing.T, evtType EventType, payload any) *TraceEvent {
+	t.Helper()
+
+	id, err := peer.Decode(testCodecPeerID)
+	if err != nil {
+		t.Fatalf("decode test peer id: %v", err)
+	}
+
+	return &TraceEvent{
+		Version:   TraceEventSchemaVersion,
+		NetworkID: "test-net",
+		HostID:    id.String(),
+		Type:      evtType,
+		PeerID:    id,
+		// A fixed timestamp keeps the fixture deterministic; codecs
+		// round-trip it via UnixNano.
+		Timestamp: time.Unix(1700000000, 0),
+		Payload:   payload,
+	}
+}
+
+// codecRoundTripCases covers one representative EventType per fieldKind
+// eventSchemas uses, plus the RejectReason enum and the RECV_RPC JSON
+// fallback path.
+func codecRoundTripCases(t *testing.T) []struct {
+	name    string
+	evtType EventType
+	payload any
+} {
+	t.Helper()
+
+	return []struct {
+		name    string
+		evtType EventType
+		payload any
+	}{
+		{
+			name:    "add peer (plain strings)",
+			evtType: EventTypeAddPeer,
+			payload: map[string]any{"PeerID": "QmPeer", "Protocol": "/hermes/1.0.0"},
+		},
+		{
+			name:    "deliver message (string + bool + varint)",
+			evtType: EventTypeDeliverMessage,
+			payload: map[string]any{
+				"PeerID": "QmPeer", "Topic": "beacon_block", "MsgID": "deadbeef",
+				"Local": true, "MsgSize": 1234, "Seq": "0102",
+			},
+		},
+		{
+			name:    "reject message (RejectReason enum)",
+			evtType: EventTypeRejectMessage,
+			payload: map[string]any{
+				"PeerID": "QmPeer", "Topic": "beacon_block", "MsgID": "deadbeef",
+				// Local/MsgSize/Seq must be non-zero: protobufEncodePayload
+				// omits zero-value fields per proto3 semantics (see
+				// TestProtobufCodecOmitsZeroValueFields), and this fixture is
+				// checked for every schema field surviving the round trip.
+				"Reason": "validation failed", "Local": true, "MsgSize": 42, "Seq": "0102",
+			},
+		},
+		{
+			name:    "recv ihave (repeated string)",
+			evtType: EventTypeRecvIHAVE,
+			payload: map[string]any{"PeerID": "QmPeer", "Topic": "beacon_block", "MsgIDs": []string{"a", "b", "c"}},
+		},
+		{
+			name:    "recv prune (varint + repeated string)",
+			evtType: EventTypeRecvPrune,
+			payload: map[string]any{"PeerID": "QmPeer", "Topic": "beacon_block", "Backoff": int64(60), "Peers": []string{"QmA", "QmB"}},
+		},
+		{
+			name:    "beacon block (uint64 varints)",
+			evtType: EventTypeBeaconBlock,
+			payload: map[string]any{"Slot": uint64(123), "ProposerIndex": uint64(7), "BlockRoot": "0xabc", "ParentRoot": "0xdef"},
+		},
+		{
+			name:    "recv rpc (opaque JSON fallback)",
+			evtType: EventTypeRecvRPC,
+			payload: map[string]any{"AnythingNewRPCMetaReturns": []any{"x", "y"}, "N": float64(3)},
+		},
+	}
+}
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	codec := JSONCodec{}
+
+	for _, tc := range codecRoundTripCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			in := testCodecEvent(t, tc.evtType, tc.payload)
+
+			data, err := codec.Encode(in)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			out, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			assertEnvelopeEqual(t, in, out)
+
+			inPayload, ok := tc.payload.(map[string]any)
+			if !ok {
+				return
+			}
+			outPayload, ok := out.Payload.(map[string]any)
+			if !ok {
+				t.Fatalf("decoded Payload type = %T, want map[string]any", out.Payload)
+			}
+			for k := range inPayload {
+				if _, ok := outPayload[k]; !ok {
+					t.Errorf("decoded payload missing field %q present in the original", k)
+				}
+			}
+		})
+	}
+}
+
+func TestProtobufCodecRoundTrip(t *testing.T) {
+	codec := ProtobufCodec{}
+
+	for _, tc := range codecRoundTripCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			in := testCodecEvent(t, tc.evtType, tc.payload)
+
+			data, err := codec.Encode(in)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+
+			out, err := codec.Decode(data)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			assertEnvelopeEqual(t, in, out)
+
+			schema, hasSchema := eventSchemas[tc.evtType]
+			if !hasSchema {
+				return // RECV_RPC/SEND_RPC/DROP_RPC: opaque JSON fallback, no typed schema to check.
+			}
+
+			payload, ok := out.Payload.(map[string]any)
+			if !ok {
+				t.Fatalf("decoded Payload type = %T, want map[string]any", out.Payload)
+			}
+			for _, f := range schema.fields {
+				if _, ok := payload[f.key]; !ok {
+					t.Errorf("decoded payload missing field %q present in the original", f.key)
+				}
+			}
+		})
+	}
+}
+
+func TestProtobufCodecOmitsZeroValueFields(t *testing.T) {
+	codec := ProtobufCodec{}
+
+	in := testCodecEvent(t, EventTypeThrottlePeer, map[string]any{"PeerID": ""})
+
+	data, err := codec.Encode(in)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	out, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	payload, _ := out.Payload.(map[string]any)
+	if _, ok := payload["PeerID"]; ok {
+		t.Fatalf("expected an empty string field to be omitted from the wire (proto3 semantics), got %v", payload)
+	}
+}
+
+func TestParseRejectReasonRoundTrip(t *testing.T) {
+	for reason, s := range rejectReasonStrings {
+		if got := parseRejectReason(s); got != reason {
+			t.Errorf("parseRejectReason(%q) = %v, want %v", s, got, reason)
+		}
+		if got := reason.String(); got != s {
+			t.Errorf("RejectReason(%v).String() = %q, want %q", reason, got, s)
+		}
+	}
+
+	if got := parseRejectReason("some future reason libp2p-pubsub hasn't invented yet"); got != RejectReasonUnspecified {
+		t.Errorf("parseRejectReason(unknown) = %v, want RejectReasonUnspecified", got)
+	}
+}
+
+func assertEnvelopeEqual(t *testing.T, in, out *TraceEvent) {
+	t.Helper()
+
+	if out.Version != in.Version {
+		t.Errorf("Version = %d, want %d", out.Version, in.Version)
+	}
+	if out.NetworkID != in.NetworkID {
+		t.Errorf("NetworkID = %q, want %q", out.NetworkID, in.NetworkID)
+	}
+	if out.HostID != in.HostID {
+		t.Errorf("HostID = %q, want %q", out.HostID, in.HostID)
+	}
+	if out.Type != in.Type {
+		t.Errorf("Type = %q, want %q", out.Type, in.Type)
+	}
+	if out.PeerID != in.PeerID {
+		t.Errorf("PeerID = %v, want %v", out.PeerID, in.PeerID)
+	}
+	if !out.Timestamp.Equal(in.Timestamp) {
+		t.Errorf("Timestamp = %v, want %v", out.Timestamp, in.Timestamp)
+	}
+}
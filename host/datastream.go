@@ -0,0 +1,118 @@
+package host
+
+import (
+	"context"
+	"fmt"
+)
+
+// DataStream abstracts the sink that TraceEvents are shipped to. Hermes ships
+// with backends for AWS Kinesis, Kafka, NATS JetStream, a local rotating
+// JSONL file, and stdout (mainly for local development/debugging). Each
+// implementation owns its own batching and retry/backoff behavior so that
+// FlushTraceWithTimestamp can treat every backend the same way.
+type DataStream interface {
+	// PutEvent submits evt to the underlying sink. Implementations should
+	// respect ctx's deadline and return a non-nil error if the event could
+	// not be (queued for) delivery.
+	PutEvent(ctx context.Context, evt *TraceEvent) error
+
+	// Type returns a short, lower-case identifier for the backend, e.g.
+	// "kinesis", "kafka", "nats", "file", "stdout". Used for logging and
+	// metrics labels.
+	Type() string
+
+	// Close flushes any buffered events and releases underlying resources
+	// (connections, file handles, producers, ...). Callers should invoke it
+	// during host shutdown.
+	Close() error
+}
+
+// PartitionKeyStrategy selects how a partition/routing key is derived for a
+// TraceEvent. Not every backend uses a partition key (e.g. stdout/file
+// don't), but the ones that do (Kinesis, Kafka) share the same strategies.
+type PartitionKeyStrategy string
+
+const (
+	// PartitionKeyStrategyPeerID routes all events emitted by the same
+	// Hermes host to the same partition, which keeps a given peer's trace
+	// in order downstream.
+	PartitionKeyStrategyPeerID PartitionKeyStrategy = "peer-id"
+
+	// PartitionKeyStrategyRandom spreads events evenly across partitions at
+	// the cost of per-peer ordering.
+	PartitionKeyStrategyRandom PartitionKeyStrategy = "random"
+
+	// PartitionKeyStrategyEventType groups events by EventType so a single
+	// consumer can be scaled to a high-volume event type independently of
+	// the rest.
+	PartitionKeyStrategyEventType PartitionKeyStrategy = "event-type"
+)
+
+// DataStreamType identifies which DataStream implementation to construct.
+type DataStreamType string
+
+const (
+	DataStreamTypeKinesis DataStreamType = "kinesis"
+	DataStreamTypeKafka   DataStreamType = "kafka"
+	DataStreamTypeNATS    DataStreamType = "nats"
+	DataStreamTypeFile    DataStreamType = "file"
+	DataStreamTypeStdout  DataStreamType = "stdout"
+	DataStreamTypeNoop    DataStreamType = "noop"
+)
+
+// DataStreamConfig carries the settings for every supported DataStream
+// backend. Only the fields relevant to Type are read; the rest are ignored.
+// This mirrors how HostConfig groups together optional, backend-specific
+// settings elsewhere in Hermes.
+type DataStreamConfig struct {
+	Type DataStreamType
+
+	PartitionKeyStrategy PartitionKeyStrategy
+
+	// Codec selects the wire encoding used to serialize TraceEvents before
+	// handing them to the backend. Defaults to JSONCodec.
+	Codec TraceEventCodec
+
+	Kinesis *KinesisDataStreamConfig
+	Kafka   *KafkaDataStreamConfig
+	NATS    *NATSDataStreamConfig
+	File    *FileDataStreamConfig
+}
+
+// NewDataStream constructs the DataStream backend selected by cfg.Type.
+func NewDataStream(cfg *DataStreamConfig) (DataStream, error) {
+	if cfg == nil || cfg.Type == "" || cfg.Type == DataStreamTypeNoop {
+		return NoopDataStream{}, nil
+	}
+
+	codec := cfg.Codec
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	switch cfg.Type {
+	case DataStreamTypeKinesis:
+		return NewKinesisDataStream(cfg.Kinesis, cfg.PartitionKeyStrategy, codec)
+	case DataStreamTypeKafka:
+		return NewKafkaDataStream(cfg.Kafka, cfg.PartitionKeyStrategy, codec)
+	case DataStreamTypeNATS:
+		return NewNATSDataStream(cfg.NATS, codec)
+	case DataStreamTypeFile:
+		return NewFileDataStream(cfg.File, codec)
+	case DataStreamTypeStdout:
+		return NewStdoutDataStream(codec), nil
+	default:
+		return nil, fmt.Errorf("unknown data stream type %q", cfg.Type)
+	}
+}
+
+// NoopDataStream discards every event. It's the zero-value DataStream used
+// when no sink is configured, so Host can always assume cfg.DataStream is
+// non-nil.
+type NoopDataStream struct{}
+
+var _ DataStream = NoopDataStream{}
+
+func (NoopDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error { return nil }
+func (NoopDataStream) Type() string                                       { return "noop" }
+func (NoopDataStream) Close() error                                       { return nil }
@@ -0,0 +1,127 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultFileDataStreamMaxBytes rotates a segment once it exceeds this size.
+const defaultFileDataStreamMaxBytes = 128 * 1024 * 1024 // 128 MiB
+
+// FileDataStreamConfig configures the local rotating JSONL DataStream
+// backend, mainly useful for operators without access to a managed
+// streaming platform.
+type FileDataStreamConfig struct {
+	Dir string
+
+	// MaxSegmentBytes rotates to a new file once the current one grows past
+	// this size. Defaults to defaultFileDataStreamMaxBytes.
+	MaxSegmentBytes int64
+}
+
+// FileDataStream appends one TraceEvent per line, encoded with the
+// configured codec (JSONCodec by default), to a size-rotated segment file
+// under Dir.
+type FileDataStream struct {
+	dir         string
+	maxBytes    int64
+	codec       TraceEventCodec
+	mu          sync.Mutex
+	file        *os.File
+	writtenSize int64
+}
+
+var _ DataStream = (*FileDataStream)(nil)
+
+// NewFileDataStream constructs a DataStream backed by rotating local JSONL
+// segments.
+func NewFileDataStream(cfg *FileDataStreamConfig, codec TraceEventCodec) (*FileDataStream, error) {
+	if cfg == nil || cfg.Dir == "" {
+		return nil, fmt.Errorf("file data stream requires a directory")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data stream dir: %w", err)
+	}
+
+	maxBytes := cfg.MaxSegmentBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultFileDataStreamMaxBytes
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	ds := &FileDataStream{dir: cfg.Dir, maxBytes: maxBytes, codec: codec}
+	if err := ds.rotate(); err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+func (ds *FileDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error {
+	data, err := ds.codec.Encode(evt)
+	if err != nil {
+		return fmt.Errorf("encode trace event: %w", err)
+	}
+	data = append(data, '\n')
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.writtenSize+int64(len(data)) > ds.maxBytes {
+		if err := ds.rotateLocked(); err != nil {
+			return err
+		}
+	}
+
+	n, err := ds.file.Write(data)
+	if err != nil {
+		return fmt.Errorf("write trace event: %w", err)
+	}
+	ds.writtenSize += int64(n)
+
+	return nil
+}
+
+func (ds *FileDataStream) rotate() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	return ds.rotateLocked()
+}
+
+func (ds *FileDataStream) rotateLocked() error {
+	if ds.file != nil {
+		if err := ds.file.Close(); err != nil {
+			return fmt.Errorf("close previous segment: %w", err)
+		}
+	}
+
+	name := fmt.Sprintf("hermes-trace-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000"))
+	f, err := os.OpenFile(filepath.Join(ds.dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create segment: %w", err)
+	}
+
+	ds.file = f
+	ds.writtenSize = 0
+
+	return nil
+}
+
+func (ds *FileDataStream) Type() string { return string(DataStreamTypeFile) }
+
+func (ds *FileDataStream) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.file == nil {
+		return nil
+	}
+	return ds.file.Close()
+}
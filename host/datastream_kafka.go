@@ -0,0 +1,101 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaDataStreamConfig configures the Kafka DataStream backend.
+type KafkaDataStreamConfig struct {
+	Brokers []string
+	Topic   string
+
+	// BatchSize/BatchTimeout are passed straight through to the underlying
+	// kafka-go Writer, which batches and retries on our behalf.
+	BatchSize    int
+	BatchTimeout int // milliseconds
+}
+
+// KafkaDataStream ships TraceEvents to a Kafka topic using segmentio/kafka-go.
+type KafkaDataStream struct {
+	writer   *kafka.Writer
+	keyStrat PartitionKeyStrategy
+	codec    TraceEventCodec
+}
+
+var _ DataStream = (*KafkaDataStream)(nil)
+
+// NewKafkaDataStream constructs a DataStream backed by Kafka.
+func NewKafkaDataStream(cfg *KafkaDataStreamConfig, keyStrat PartitionKeyStrategy, codec TraceEventCodec) (*KafkaDataStream, error) {
+	if cfg == nil || len(cfg.Brokers) == 0 || cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka data stream requires brokers and a topic")
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		Async:        false,
+	}
+	if cfg.BatchSize > 0 {
+		w.BatchSize = cfg.BatchSize
+	}
+	if cfg.BatchTimeout > 0 {
+		w.BatchTimeout = time.Duration(cfg.BatchTimeout) * time.Millisecond
+	}
+
+	if keyStrat == "" {
+		keyStrat = PartitionKeyStrategyPeerID
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	return &KafkaDataStream{writer: w, keyStrat: keyStrat, codec: codec}, nil
+}
+
+func (ds *KafkaDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error {
+	data, err := ds.codec.Encode(evt)
+	if err != nil {
+		return fmt.Errorf("encode trace event: %w", err)
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(ds.partitionKey(evt)),
+		Value: data,
+	}
+
+	if err := ds.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("write kafka message: %w", err)
+	}
+
+	return nil
+}
+
+func (ds *KafkaDataStream) partitionKey(evt *TraceEvent) string {
+	switch ds.keyStrat {
+	case PartitionKeyStrategyEventType:
+		return string(evt.Type)
+	case PartitionKeyStrategyRandom:
+		u, err := uuid.NewUUID()
+		if err != nil {
+			return evt.PeerID.String()
+		}
+		return u.String()
+	case PartitionKeyStrategyPeerID:
+		fallthrough
+	default:
+		return evt.PeerID.String()
+	}
+}
+
+func (ds *KafkaDataStream) Type() string { return string(DataStreamTypeKafka) }
+
+func (ds *KafkaDataStream) Close() error {
+	return ds.writer.Close()
+}
@@ -0,0 +1,98 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	gk "github.com/dennis-tra/go-kinesis"
+	"github.com/google/uuid"
+	"github.com/probe-lab/hermes/tele"
+)
+
+// KinesisDataStreamConfig configures the AWS Kinesis DataStream backend.
+type KinesisDataStreamConfig struct {
+	StreamName string
+	Region     string
+}
+
+// KinesisDataStream ships TraceEvents to an AWS Kinesis data stream via
+// go-kinesis, which handles batching and retry/backoff internally.
+type KinesisDataStream struct {
+	producer *gk.Producer
+	keyStrat PartitionKeyStrategy
+	codec    TraceEventCodec
+}
+
+var _ DataStream = (*KinesisDataStream)(nil)
+
+// NewKinesisDataStream constructs a DataStream backed by AWS Kinesis.
+func NewKinesisDataStream(cfg *KinesisDataStreamConfig, keyStrat PartitionKeyStrategy, codec TraceEventCodec) (*KinesisDataStream, error) {
+	if cfg == nil || cfg.StreamName == "" {
+		return nil, fmt.Errorf("kinesis data stream requires a stream name")
+	}
+
+	producer, err := gk.NewProducer(cfg.StreamName, gk.WithRegion(cfg.Region))
+	if err != nil {
+		return nil, fmt.Errorf("new kinesis producer: %w", err)
+	}
+
+	if keyStrat == "" {
+		keyStrat = PartitionKeyStrategyPeerID
+	}
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	return &KinesisDataStream{producer: producer, keyStrat: keyStrat, codec: codec}, nil
+}
+
+func (ds *KinesisDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error {
+	return ds.producer.PutRecord(ctx, &kinesisRecord{evt: evt, keyStrat: ds.keyStrat, codec: ds.codec})
+}
+
+func (ds *KinesisDataStream) Type() string { return string(DataStreamTypeKinesis) }
+
+func (ds *KinesisDataStream) Close() error {
+	return ds.producer.Close()
+}
+
+// kinesisRecord adapts a TraceEvent to the gk.Record interface that
+// go-kinesis's producer expects.
+type kinesisRecord struct {
+	evt      *TraceEvent
+	keyStrat PartitionKeyStrategy
+	codec    TraceEventCodec
+}
+
+var _ gk.Record = (*kinesisRecord)(nil)
+
+func (r *kinesisRecord) PartitionKey() string {
+	switch r.keyStrat {
+	case PartitionKeyStrategyEventType:
+		return string(r.evt.Type)
+	case PartitionKeyStrategyRandom:
+		u, err := uuid.NewUUID()
+		if err != nil {
+			return r.evt.PeerID.String()
+		}
+		return u.String()
+	case PartitionKeyStrategyPeerID:
+		fallthrough
+	default:
+		return r.evt.PeerID.String()
+	}
+}
+
+func (r *kinesisRecord) ExplicitHashKey() *string {
+	return nil
+}
+
+func (r *kinesisRecord) Data() []byte {
+	data, err := r.codec.Encode(r.evt)
+	if err != nil {
+		slog.Warn("Failed to encode trace event", tele.LogAttrError(err))
+		return nil
+	}
+	return data
+}
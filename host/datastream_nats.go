@@ -0,0 +1,90 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// defaultNATSSetupTimeout bounds the one-off stream creation/update call
+// made while constructing a NATSDataStream.
+const defaultNATSSetupTimeout = 10 * time.Second
+
+// NATSDataStreamConfig configures the NATS JetStream DataStream backend.
+type NATSDataStreamConfig struct {
+	URL     string
+	Subject string
+	Stream  string
+}
+
+// NATSDataStream ships TraceEvents to a NATS JetStream subject. JetStream
+// handles at-least-once delivery and retry/backoff to the stream on our
+// behalf; we just need to make sure the stream exists before publishing.
+type NATSDataStream struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	subject string
+	codec   TraceEventCodec
+}
+
+var _ DataStream = (*NATSDataStream)(nil)
+
+// NewNATSDataStream constructs a DataStream backed by NATS JetStream.
+func NewNATSDataStream(cfg *NATSDataStreamConfig, codec TraceEventCodec) (*NATSDataStream, error) {
+	if cfg == nil || cfg.URL == "" || cfg.Subject == "" {
+		return nil, fmt.Errorf("nats data stream requires a url and a subject")
+	}
+
+	conn, err := nats.Connect(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("new jetstream context: %w", err)
+	}
+
+	if cfg.Stream != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultNATSSetupTimeout)
+		defer cancel()
+
+		_, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:     cfg.Stream,
+			Subjects: []string{cfg.Subject},
+		})
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("create or update jetstream stream %s: %w", cfg.Stream, err)
+		}
+	}
+
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	return &NATSDataStream{conn: conn, js: js, subject: cfg.Subject, codec: codec}, nil
+}
+
+func (ds *NATSDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error {
+	data, err := ds.codec.Encode(evt)
+	if err != nil {
+		return fmt.Errorf("encode trace event: %w", err)
+	}
+
+	if _, err := ds.js.Publish(ctx, ds.subject, data); err != nil {
+		return fmt.Errorf("publish to jetstream: %w", err)
+	}
+
+	return nil
+}
+
+func (ds *NATSDataStream) Type() string { return string(DataStreamTypeNATS) }
+
+func (ds *NATSDataStream) Close() error {
+	return ds.conn.Drain()
+}
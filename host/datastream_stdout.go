@@ -0,0 +1,49 @@
+package host
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdoutDataStream writes one TraceEvent per line, encoded with the
+// configured codec (JSONCodec by default), to an output writer (stdout by
+// default). Mainly useful for local development: piping
+// `hermes ... | jq` to eyeball trace events without standing up a sink.
+type StdoutDataStream struct {
+	mu    sync.Mutex
+	w     io.Writer
+	codec TraceEventCodec
+}
+
+var _ DataStream = (*StdoutDataStream)(nil)
+
+// NewStdoutDataStream constructs a DataStream that writes to os.Stdout.
+func NewStdoutDataStream(codec TraceEventCodec) *StdoutDataStream {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &StdoutDataStream{w: os.Stdout, codec: codec}
+}
+
+func (ds *StdoutDataStream) PutEvent(ctx context.Context, evt *TraceEvent) error {
+	data, err := ds.codec.Encode(evt)
+	if err != nil {
+		return fmt.Errorf("encode trace event: %w", err)
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if _, err := ds.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write trace event: %w", err)
+	}
+
+	return nil
+}
+
+func (ds *StdoutDataStream) Type() string { return string(DataStreamTypeStdout) }
+
+func (ds *StdoutDataStream) Close() error { return nil }
@@ -0,0 +1,60 @@
+package host
+
+// EventType identifies the kind of pubsub event recorded in a TraceEvent.
+type EventType string
+
+const (
+	EventTypeAddPeer              EventType = "ADD_PEER"
+	EventTypeRemovePeer           EventType = "REMOVE_PEER"
+	EventTypeJoin                 EventType = "JOIN"
+	EventTypeLeave                EventType = "LEAVE"
+	EventTypeGraft                EventType = "GRAFT"
+	EventTypePrune                EventType = "PRUNE"
+	EventTypeValidateMessage      EventType = "VALIDATE_MESSAGE"
+	EventTypeDeliverMessage       EventType = "DELIVER_MESSAGE"
+	EventTypeRejectMessage        EventType = "REJECT_MESSAGE"
+	EventTypeDuplicateMessage     EventType = "DUPLICATE_MESSAGE"
+	EventTypeThrottlePeer         EventType = "THROTTLE_PEER"
+	EventTypeUndeliverableMessage EventType = "UNDELIVERABLE_MESSAGE"
+	EventTypePublishMessage       EventType = "PUBLISH_MESSAGE"
+	EventTypeRecvRPC              EventType = "RECV_RPC"
+	EventTypeSendRPC              EventType = "SEND_RPC"
+	EventTypeDropRPC              EventType = "DROP_RPC"
+
+	// The EventType{Recv,Send,Drop}* constants below are emitted in addition
+	// to EventType{Recv,Send,Drop}RPC (see rpc_trace.go): one per control
+	// message found in the RPC, so a downstream analyzer can reconstruct
+	// gossipsub mesh dynamics (IHAVE/IWANT gossip, GRAFT/PRUNE mesh churn,
+	// ...) without parsing a monolithic RPC blob.
+	EventTypeRecvSubscribe EventType = "RECV_SUBSCRIBE"
+	EventTypeRecvPublish   EventType = "RECV_PUBLISH"
+	EventTypeRecvIHAVE     EventType = "RECV_IHAVE"
+	EventTypeRecvIWANT     EventType = "RECV_IWANT"
+	EventTypeRecvGraft     EventType = "RECV_GRAFT"
+	EventTypeRecvPrune     EventType = "RECV_PRUNE"
+	EventTypeRecvIDontWant EventType = "RECV_IDONTWANT"
+
+	EventTypeSendSubscribe EventType = "SEND_SUBSCRIBE"
+	EventTypeSendPublish   EventType = "SEND_PUBLISH"
+	EventTypeSendIHAVE     EventType = "SEND_IHAVE"
+	EventTypeSendIWANT     EventType = "SEND_IWANT"
+	EventTypeSendGraft     EventType = "SEND_GRAFT"
+	EventTypeSendPrune     EventType = "SEND_PRUNE"
+	EventTypeSendIDontWant EventType = "SEND_IDONTWANT"
+
+	EventTypeDropSubscribe EventType = "DROP_SUBSCRIBE"
+	EventTypeDropPublish   EventType = "DROP_PUBLISH"
+	EventTypeDropIHAVE     EventType = "DROP_IHAVE"
+	EventTypeDropIWANT     EventType = "DROP_IWANT"
+	EventTypeDropGraft     EventType = "DROP_GRAFT"
+	EventTypeDropPrune     EventType = "DROP_PRUNE"
+	EventTypeDropIDontWant EventType = "DROP_IDONTWANT"
+
+	// EventTypeBeaconBlock and EventTypeBlobSidecar are emitted alongside
+	// the generic EventTypeDeliverMessage/EventTypeValidateMessage events
+	// for beacon_block/blob_sidecar_N gossip messages (see
+	// beacon_trace.go), carrying their decoded SSZ fields so downstream
+	// analytics don't need to re-parse the raw payload.
+	EventTypeBeaconBlock EventType = "BEACON_BLOCK"
+	EventTypeBlobSidecar EventType = "BLOB_SIDECAR"
+)
@@ -3,49 +3,37 @@ package host
 import (
 	"context"
 	"encoding/hex"
-	"encoding/json"
 	"log/slog"
 	"time"
 
-	gk "github.com/dennis-tra/go-kinesis"
-	"github.com/google/uuid"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	pubsubpb "github.com/libp2p/go-libp2p-pubsub/pb"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
 	"github.com/probe-lab/hermes/tele"
 )
 
+// TraceEventSchemaVersion is bumped whenever the TraceEvent envelope's shape
+// changes in a way that downstream consumers need to branch on.
+const TraceEventSchemaVersion = 1
+
+// TraceEvent is the schema-versioned envelope Hermes ships to the configured
+// DataStream. Version, NetworkID and HostID live at the top level (rather
+// than inside Payload) so consumers can route/filter events without
+// unmarshalling the backend-specific payload first.
 type TraceEvent struct {
+	Version   int
+	NetworkID string
+	HostID    string
 	Type      EventType
 	PeerID    peer.ID
 	Timestamp time.Time
-	Payload   any `json:"Data"` // cannot use field "Data" because of gk.Record method
-}
-
-func (t *TraceEvent) PartitionKey() string {
-	u, err := uuid.NewUUID()
-	if err != nil {
-		return t.PeerID.String()
-	}
-	return u.String()
-}
-
-func (t *TraceEvent) ExplicitHashKey() *string {
-	return nil
+	Payload   any
 }
 
-func (t *TraceEvent) Data() []byte {
-	data, err := json.Marshal(t)
-	if err != nil {
-		slog.Warn("Failed to marshal trace event", tele.LogAttrError(err))
-		return nil
-	}
-	return data
-}
-
-var _ gk.Record = (*TraceEvent)(nil)
-
 var _ pubsub.RawTracer = (*Host)(nil)
 
 func (h *Host) FlushTrace(evtType EventType, payload any) {
@@ -53,21 +41,56 @@ func (h *Host) FlushTrace(evtType EventType, payload any) {
 }
 
 func (h *Host) FlushTraceWithTimestamp(evtType EventType, timestamp time.Time, payload any) {
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	if h.sampler != nil {
+		decision := SampleDecisionSampled
+		allow := h.sampler.Allow(evtType, payload)
+		if !allow {
+			decision = SampleDecisionDropped
+		}
+
+		h.meterSampledTraces.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("type", string(evtType)),
+			attribute.String("decision", string(decision)),
+		))
+
+		if !allow {
+			return
+		}
+	}
+
 	evt := &TraceEvent{
+		Version:   TraceEventSchemaVersion,
+		NetworkID: h.cfg.NetworkID,
+		HostID:    h.ID().String(),
 		Type:      evtType,
 		PeerID:    h.ID(),
 		Timestamp: timestamp,
 		Payload:   payload,
 	}
 
-	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
-	defer cancel()
+	var walHandle walEntryHandle
+	hasWALHandle := false
+	if h.wal != nil {
+		handle, err := h.wal.Append(evt)
+		if err != nil {
+			slog.Warn("Failed to append trace event to WAL", tele.LogAttrError(err))
+		} else {
+			walHandle, hasWALHandle = handle, true
+		}
+	}
 
 	if err := h.cfg.DataStream.PutEvent(ctx, evt); err != nil {
 		slog.Warn("Failed to put trace event payload", tele.LogAttrError(err))
 		return
 	}
 
+	if hasWALHandle {
+		h.wal.Ack(walHandle)
+	}
+
 	h.meterSubmittedTraces.Add(ctx, 1)
 }
 
@@ -161,18 +184,6 @@ func (h *Host) ThrottlePeer(p peer.ID) {
 	})
 }
 
-func (h *Host) RecvRPC(rpc *pubsub.RPC) {
-	// handled in EventTracer
-}
-
-func (h *Host) SendRPC(rpc *pubsub.RPC, p peer.ID) {
-	// handled in EventTracer
-}
-
-func (h *Host) DropRPC(rpc *pubsub.RPC, p peer.ID) {
-	// handled in EventTracer
-}
-
 func (h *Host) UndeliverableMessage(msg *pubsub.Message) {
 	h.FlushTrace(EventTypeUndeliverableMessage, map[string]any{
 		"PeerID": msg.ReceivedFrom,
@@ -191,8 +202,20 @@ func (h *Host) Trace(evt *pubsubpb.TraceEvent) {
 			"Topic": evt.GetPublishMessage().GetTopic(),
 		})
 	case pubsubpb.TraceEvent_RECV_RPC:
-		payload := newRPCMeta(evt.GetRecvRPC().GetReceivedFrom(), evt.GetRecvRPC().GetMeta())
+		recv := evt.GetRecvRPC()
+		payload := newRPCMeta(recv.GetReceivedFrom(), recv.GetMeta())
 		h.FlushTraceWithTimestamp(EventTypeRecvRPC, ts, payload)
+
+		// newRPCMeta/EventTypeRecvRPC above is the coarse per-RPC summary;
+		// the per-control-message expansion needs the sender as a peer.ID,
+		// which RawTracer.RecvRPC (rpc_trace.go) structurally can't supply -
+		// this pb.TraceEvent, via the EventTracer pathway, is the one place
+		// recv-direction control messages and the sender are both available.
+		if from, err := peer.IDFromBytes(recv.GetReceivedFrom()); err != nil {
+			slog.Warn("Failed to decode RecvRPC sender for control-message tracing", tele.LogAttrError(err))
+		} else {
+			h.traceRecvControlMessages(from, recv.GetMeta())
+		}
 	case pubsubpb.TraceEvent_SEND_RPC:
 		payload := newRPCMeta(evt.GetSendRPC().GetSendTo(), evt.GetSendRPC().GetMeta())
 		h.FlushTraceWithTimestamp(EventTypeSendRPC, ts, payload)
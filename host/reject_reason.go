@@ -0,0 +1,65 @@
+package host
+
+// RejectReason mirrors go-libp2p-pubsub's validation-result reject reasons
+// (the strings it passes to RawTracer.RejectMessage) as a closed enum, so
+// codecs that want typed fidelity - see ProtobufCodec/AvroCodec - don't have
+// to ship an open-ended string across the wire. Keep in sync with
+// pb/trace.proto's RejectReason enum.
+type RejectReason int32
+
+const (
+	RejectReasonUnspecified RejectReason = iota
+	RejectReasonValidationThrottled
+	RejectReasonValidationIgnored
+	RejectReasonValidationFailed
+	RejectReasonMissingSignature
+	RejectReasonInvalidSignature
+	RejectReasonUnexpectedSignature
+	RejectReasonUnexpectedAuthInfo
+	RejectReasonSelfOrigin
+	RejectReasonBlacklistedPeer
+	RejectReasonBlacklistedSource
+	RejectReasonValidationQueueFull
+)
+
+// rejectReasonStrings are exactly the strings go-libp2p-pubsub's validation
+// pipeline passes as RawTracer.RejectMessage's reason argument.
+var rejectReasonStrings = map[RejectReason]string{
+	RejectReasonValidationThrottled: "validation throttled",
+	RejectReasonValidationIgnored:   "validation ignored",
+	RejectReasonValidationFailed:    "validation failed",
+	RejectReasonMissingSignature:    "missing signature",
+	RejectReasonInvalidSignature:    "invalid signature",
+	RejectReasonUnexpectedSignature: "unexpected signature",
+	RejectReasonUnexpectedAuthInfo:  "unexpected auth info",
+	RejectReasonSelfOrigin:          "self origin",
+	RejectReasonBlacklistedPeer:     "blacklisted peer",
+	RejectReasonBlacklistedSource:   "blacklisted source",
+	RejectReasonValidationQueueFull: "validation queue full",
+}
+
+var stringRejectReasons = func() map[string]RejectReason {
+	m := make(map[string]RejectReason, len(rejectReasonStrings))
+	for reason, s := range rejectReasonStrings {
+		m[s] = reason
+	}
+	return m
+}()
+
+// parseRejectReason converts the raw string go-libp2p-pubsub hands
+// RawTracer.RejectMessage into its closed RejectReason enum. An unrecognized
+// string (e.g. a future libp2p-pubsub release adding a new reason) maps to
+// RejectReasonUnspecified rather than failing the encode.
+func parseRejectReason(s string) RejectReason {
+	if r, ok := stringRejectReasons[s]; ok {
+		return r
+	}
+	return RejectReasonUnspecified
+}
+
+// String returns the same string go-libp2p-pubsub would have passed in, so
+// round-tripping a RejectReason through a codec reproduces the original
+// Payload["Reason"] value.
+func (r RejectReason) String() string {
+	return rejectReasonStrings[r]
+}
@@ -0,0 +1,227 @@
+package host
+
+import (
+	"encoding/hex"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pubsubpb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// rpcEventTypes groups the per-control-message EventTypes for one RPC
+// direction (recv/send/drop), keeping traceControlMessages direction-agnostic.
+type rpcEventTypes struct {
+	subscribe, publish, ihave, iwant, graft, prune, idontwant EventType
+}
+
+var (
+	recvRPCEventTypes = rpcEventTypes{
+		subscribe: EventTypeRecvSubscribe,
+		publish:   EventTypeRecvPublish,
+		ihave:     EventTypeRecvIHAVE,
+		iwant:     EventTypeRecvIWANT,
+		graft:     EventTypeRecvGraft,
+		prune:     EventTypeRecvPrune,
+		idontwant: EventTypeRecvIDontWant,
+	}
+	sendRPCEventTypes = rpcEventTypes{
+		subscribe: EventTypeSendSubscribe,
+		publish:   EventTypeSendPublish,
+		ihave:     EventTypeSendIHAVE,
+		iwant:     EventTypeSendIWANT,
+		graft:     EventTypeSendGraft,
+		prune:     EventTypeSendPrune,
+		idontwant: EventTypeSendIDontWant,
+	}
+	dropRPCEventTypes = rpcEventTypes{
+		subscribe: EventTypeDropSubscribe,
+		publish:   EventTypeDropPublish,
+		ihave:     EventTypeDropIHAVE,
+		iwant:     EventTypeDropIWANT,
+		graft:     EventTypeDropGraft,
+		prune:     EventTypeDropPrune,
+		idontwant: EventTypeDropIDontWant,
+	}
+)
+
+// RecvRPC is a no-op: unlike SendRPC/DropRPC, the RawTracer interface
+// doesn't hand this hook the remote peer, and PeerID is the one field a
+// downstream mesh-dynamics analysis can't do without. The recv-direction
+// per-control-message expansion instead happens in Trace
+// (flush_tracer.go), off the EventTracer pb.TraceEvent_RecvRPC payload,
+// which does carry the sender - see traceRecvControlMessages.
+func (h *Host) RecvRPC(rpc *pubsub.RPC) {}
+
+func (h *Host) SendRPC(rpc *pubsub.RPC, p peer.ID) {
+	h.traceControlMessages(sendRPCEventTypes, p, rpc)
+}
+
+func (h *Host) DropRPC(rpc *pubsub.RPC, p peer.ID) {
+	h.traceControlMessages(dropRPCEventTypes, p, rpc)
+}
+
+// traceControlMessages expands rpc into one FlushTrace call per control
+// message it carries (subscriptions, publishes, IHAVE, IWANT, GRAFT, PRUNE,
+// IDONTWANT), using the EventTypes in types for the given direction. Message
+// IDs are hex-encoded, matching the MsgID encoding ValidateMessage/
+// DeliverMessage/RejectMessage/DuplicateMessage already use in
+// flush_tracer.go, so a downstream analyzer can join a gossip advertisement
+// against the delivery event for the same message.
+func (h *Host) traceControlMessages(types rpcEventTypes, p peer.ID, rpc *pubsub.RPC) {
+	for _, sub := range rpc.GetSubscriptions() {
+		h.FlushTrace(types.subscribe, map[string]any{
+			"PeerID":    encodePeerID(p),
+			"Topic":     sub.GetTopicid(),
+			"Subscribe": sub.GetSubscribe(),
+		})
+	}
+
+	for _, msg := range rpc.GetPublish() {
+		h.FlushTrace(types.publish, map[string]any{
+			"PeerID":  encodePeerID(p),
+			"Topic":   msg.GetTopic(),
+			"MsgSize": len(msg.GetData()),
+		})
+	}
+
+	ctrl := rpc.GetControl()
+	if ctrl == nil {
+		return
+	}
+
+	for _, ihave := range ctrl.GetIhave() {
+		h.FlushTrace(types.ihave, map[string]any{
+			"PeerID": encodePeerID(p),
+			"Topic":  ihave.GetTopicID(),
+			"MsgIDs": encodeMsgIDs(ihave.GetMessageIDs()),
+		})
+	}
+
+	for _, iwant := range ctrl.GetIwant() {
+		h.FlushTrace(types.iwant, map[string]any{
+			"PeerID": encodePeerID(p),
+			"MsgIDs": encodeMsgIDs(iwant.GetMessageIDs()),
+		})
+	}
+
+	for _, graft := range ctrl.GetGraft() {
+		h.FlushTrace(types.graft, map[string]any{
+			"PeerID": encodePeerID(p),
+			"Topic":  graft.GetTopicID(),
+		})
+	}
+
+	for _, prune := range ctrl.GetPrune() {
+		peers := make([]string, 0, len(prune.GetPeers()))
+		for _, pi := range prune.GetPeers() {
+			id, err := peer.IDFromBytes(pi.GetPeerID())
+			if err != nil {
+				continue
+			}
+			peers = append(peers, id.String())
+		}
+		h.FlushTrace(types.prune, map[string]any{
+			"PeerID":  encodePeerID(p),
+			"Topic":   prune.GetTopicID(),
+			"Backoff": prune.GetBackoff(),
+			"Peers":   peers,
+		})
+	}
+
+	for _, idontwant := range ctrl.GetIdontwant() {
+		h.FlushTrace(types.idontwant, map[string]any{
+			"PeerID": encodePeerID(p),
+			"MsgIDs": encodeMsgIDs(idontwant.GetMessageIDs()),
+		})
+	}
+}
+
+func encodePeerID(p peer.ID) string {
+	if len(p) == 0 {
+		return ""
+	}
+	return p.String()
+}
+
+func encodeMsgIDs(ids []string) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = hex.EncodeToString([]byte(id))
+	}
+	return out
+}
+
+// traceRecvControlMessages is traceControlMessages' counterpart for the recv
+// direction: go-libp2p-pubsub's RawTracer.RecvRPC hook doesn't carry the
+// remote peer, so this expands the same per-control-message events off
+// meta - the summary Trace's EventTracer pathway already receives for
+// RECV_RPC - using from (decoded from the surrounding pb.TraceEvent, which
+// does carry the sender) as PeerID. RPCMeta is coarser than the raw
+// pubsub.RPC control messages traceControlMessages reads for send/drop: it
+// has no PRUNE backoff or exchanged-peer list, and it predates the
+// IDONTWANT control message, so neither is emitted here.
+func (h *Host) traceRecvControlMessages(from peer.ID, meta *pubsubpb.TraceEvent_RPCMeta) {
+	if meta == nil {
+		return
+	}
+
+	for _, sub := range meta.GetSubscription() {
+		h.FlushTrace(recvRPCEventTypes.subscribe, map[string]any{
+			"PeerID":    encodePeerID(from),
+			"Topic":     sub.GetTopic(),
+			"Subscribe": sub.GetSubscribe(),
+		})
+	}
+
+	for _, msg := range meta.GetMessages() {
+		h.FlushTrace(recvRPCEventTypes.publish, map[string]any{
+			"PeerID": encodePeerID(from),
+			"Topic":  msg.GetTopic(),
+			"MsgID":  hex.EncodeToString(msg.GetMessageID()),
+		})
+	}
+
+	ctrl := meta.GetControl()
+	if ctrl == nil {
+		return
+	}
+
+	for _, ihave := range ctrl.GetIhave() {
+		h.FlushTrace(recvRPCEventTypes.ihave, map[string]any{
+			"PeerID": encodePeerID(from),
+			"Topic":  ihave.GetTopic(),
+			"MsgIDs": encodeMsgIDBytes(ihave.GetMessageIDs()),
+		})
+	}
+
+	for _, iwant := range ctrl.GetIwant() {
+		h.FlushTrace(recvRPCEventTypes.iwant, map[string]any{
+			"PeerID": encodePeerID(from),
+			"MsgIDs": encodeMsgIDBytes(iwant.GetMessageIDs()),
+		})
+	}
+
+	for _, graft := range ctrl.GetGraft() {
+		h.FlushTrace(recvRPCEventTypes.graft, map[string]any{
+			"PeerID": encodePeerID(from),
+			"Topic":  graft.GetTopic(),
+		})
+	}
+
+	for _, prune := range ctrl.GetPrune() {
+		h.FlushTrace(recvRPCEventTypes.prune, map[string]any{
+			"PeerID": encodePeerID(from),
+			"Topic":  prune.GetTopic(),
+		})
+	}
+}
+
+// encodeMsgIDBytes is encodeMsgIDs for the RPCMeta types, whose message IDs
+// are already raw bytes rather than pubsub.RPC's string representation.
+func encodeMsgIDBytes(ids [][]byte) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = hex.EncodeToString(id)
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package host
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// SampleDecision records whether a trace event was kept or dropped by the
+// Sampler, used as the "decision" label on meterSampledTraces.
+type SampleDecision string
+
+const (
+	SampleDecisionSampled SampleDecision = "sampled"
+	SampleDecisionDropped SampleDecision = "dropped"
+)
+
+// SampleRule configures sampling for a single EventType. The zero value lets
+// everything through.
+type SampleRule struct {
+	// Rate is the fraction of events to keep, in [0, 1]. 0 means "unset",
+	// treated the same as 1 (keep everything) so omitting a rule is a no-op.
+	Rate float64
+
+	// RateLimit caps throughput to at most this many events/sec via a token
+	// bucket, applied after the Rate check. 0 disables the limit.
+	RateLimit float64
+
+	// Deterministic, when set, derives the sampling decision from a hash of
+	// (MsgID, PeerID) found in the event payload instead of a random draw,
+	// so every observer of the same message agrees on whether to keep it -
+	// enabling cross-node correlation of sampled datasets.
+	Deterministic bool
+}
+
+// SamplerConfig maps EventTypes to the SampleRule that should gate them.
+// EventTypes with no entry are always kept.
+type SamplerConfig struct {
+	Rules map[EventType]SampleRule
+}
+
+// Sampler gates which TraceEvents actually reach the configured DataStream.
+// It's applied inside FlushTraceWithTimestamp, before the DataStream call,
+// so a dropped event never touches the network.
+type Sampler struct {
+	rules map[EventType]SampleRule
+
+	mu       sync.Mutex
+	limiters map[EventType]*rate.Limiter
+}
+
+// NewSampler constructs a Sampler from cfg. A nil/zero-value cfg keeps every
+// event, equivalent to not configuring a sampler at all.
+func NewSampler(cfg SamplerConfig) *Sampler {
+	return &Sampler{
+		rules:    cfg.Rules,
+		limiters: make(map[EventType]*rate.Limiter, len(cfg.Rules)),
+	}
+}
+
+// Allow reports whether evt (identified by evtType, with the given payload)
+// should be submitted to the DataStream.
+func (s *Sampler) Allow(evtType EventType, payload any) bool {
+	rule, ok := s.rules[evtType]
+	if !ok {
+		return true
+	}
+
+	if !s.passesRate(evtType, rule, payload) {
+		return false
+	}
+
+	return s.passesRateLimit(evtType, rule)
+}
+
+func (s *Sampler) passesRate(evtType EventType, rule SampleRule, payload any) bool {
+	if rule.Rate <= 0 || rule.Rate >= 1 {
+		return true
+	}
+
+	if rule.Deterministic {
+		return deterministicSampleKey(payload) <= rule.Rate
+	}
+
+	return rand.Float64() < rule.Rate
+}
+
+func (s *Sampler) passesRateLimit(evtType EventType, rule SampleRule) bool {
+	if rule.RateLimit <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	limiter, ok := s.limiters[evtType]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(rule.RateLimit), int(rule.RateLimit)+1)
+		s.limiters[evtType] = limiter
+	}
+	s.mu.Unlock()
+
+	return limiter.Allow()
+}
+
+// deterministicSampleKey hashes the MsgID/PeerID found in payload (if any)
+// into a float in [0, 1), stable across processes so every observer of the
+// same message reaches the same sampling decision.
+func deterministicSampleKey(payload any) float64 {
+	m, ok := payload.(map[string]any)
+	if !ok {
+		return rand.Float64()
+	}
+
+	msgID := stringify(m["MsgID"])
+	peerID := stringify(m["PeerID"])
+	if msgID == "" && peerID == "" {
+		return rand.Float64()
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msgID))
+	_, _ = h.Write([]byte(peerID))
+
+	return float64(h.Sum32()) / (float64(^uint32(0)) + 1)
+}
@@ -0,0 +1,92 @@
+package host
+
+import (
+	"testing"
+
+	"golang.org/x/time/rate"
+)
+
+func TestSamplerAllowNoRule(t *testing.T) {
+	s := NewSampler(SamplerConfig{})
+
+	if !s.Allow(EventTypeAddPeer, nil) {
+		t.Fatal("expected event with no configured rule to be allowed")
+	}
+}
+
+func TestSamplerAllowRateZeroOrOneKeepsEverything(t *testing.T) {
+	s := NewSampler(SamplerConfig{Rules: map[EventType]SampleRule{
+		EventTypeJoin: {Rate: 0},
+		EventTypeLeave: {Rate: 1},
+	}})
+
+	for i := 0; i < 20; i++ {
+		if !s.Allow(EventTypeJoin, nil) {
+			t.Fatal("rate=0 should be treated as unset (keep everything)")
+		}
+		if !s.Allow(EventTypeLeave, nil) {
+			t.Fatal("rate=1 should keep everything")
+		}
+	}
+}
+
+func TestSamplerDeterministicSampleKeyStable(t *testing.T) {
+	payload := map[string]any{"MsgID": "abc", "PeerID": "peer-1"}
+
+	first := deterministicSampleKey(payload)
+	for i := 0; i < 10; i++ {
+		if got := deterministicSampleKey(payload); got != first {
+			t.Fatalf("deterministicSampleKey(%v) = %v, want stable %v", payload, got, first)
+		}
+	}
+
+	if first < 0 || first >= 1 {
+		t.Fatalf("deterministicSampleKey returned %v, want [0, 1)", first)
+	}
+}
+
+func TestSamplerDeterministicSampleKeyDiffersByIdentity(t *testing.T) {
+	a := deterministicSampleKey(map[string]any{"MsgID": "abc", "PeerID": "peer-1"})
+	b := deterministicSampleKey(map[string]any{"MsgID": "xyz", "PeerID": "peer-2"})
+
+	if a == b {
+		t.Fatalf("expected different (MsgID, PeerID) pairs to hash to different keys, got %v == %v", a, b)
+	}
+}
+
+func TestSamplerPassesRateDeterministicRespectsThreshold(t *testing.T) {
+	s := NewSampler(SamplerConfig{})
+
+	payload := map[string]any{"MsgID": "abc", "PeerID": "peer-1"}
+	key := deterministicSampleKey(payload)
+
+	below := SampleRule{Rate: key / 2, Deterministic: true}
+	if s.passesRate(EventTypeJoin, below, payload) {
+		t.Fatalf("expected rate below the deterministic key (%v) to reject", key)
+	}
+
+	above := SampleRule{Rate: 1, Deterministic: true}
+	if !s.passesRate(EventTypeJoin, above, payload) {
+		t.Fatal("rate=1 should always pass the deterministic check")
+	}
+}
+
+func TestSamplerPassesRateLimitEnforcesBucket(t *testing.T) {
+	s := NewSampler(SamplerConfig{})
+
+	rule := SampleRule{RateLimit: 1}
+	// Pre-install the same limiter passesRateLimit would create (burst
+	// RateLimit + 1 = 2); the rapid calls below drain that burst.
+	s.limiters[EventTypeGraft] = rate.NewLimiter(rate.Limit(rule.RateLimit), int(rule.RateLimit)+1)
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if s.passesRateLimit(EventTypeGraft, rule) {
+			allowed++
+		}
+	}
+
+	if allowed == 0 || allowed >= 5 {
+		t.Fatalf("expected the token bucket to allow some but not all of 5 rapid calls, allowed=%d", allowed)
+	}
+}
@@ -0,0 +1,409 @@
+package host
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultWALMaxSegmentBytes rotates a WAL segment once it exceeds this size.
+const defaultWALMaxSegmentBytes = 64 * 1024 * 1024 // 64 MiB
+
+// walAckMarkerSuffix names the sidecar file that durably records how many
+// leading lines of a segment have been fully acked, so ReplayWAL/Replay only
+// resubmit what's actually unacked - including within the still-active
+// segment, which Append keeps growing.
+const walAckMarkerSuffix = ".ack"
+
+// FsyncPolicy controls how aggressively WAL writes are flushed to disk.
+type FsyncPolicy string
+
+const (
+	// FsyncPolicyAlways fsyncs after every Append. Safest, slowest.
+	FsyncPolicyAlways FsyncPolicy = "always"
+
+	// FsyncPolicyInterval fsyncs on a timer (see WALConfig.FsyncInterval).
+	FsyncPolicyInterval FsyncPolicy = "interval"
+
+	// FsyncPolicyNever relies on the OS to flush dirty pages eventually.
+	// Fastest, but a crash can lose writes the OS hadn't flushed yet.
+	FsyncPolicyNever FsyncPolicy = "never"
+)
+
+// WALConfig configures the write-ahead log.
+type WALConfig struct {
+	Dir string
+
+	// MaxSegmentBytes rotates to a new segment once the current one grows
+	// past this size. Defaults to defaultWALMaxSegmentBytes.
+	MaxSegmentBytes int64
+
+	FsyncPolicy FsyncPolicy
+
+	// FsyncInterval is only used when FsyncPolicy is FsyncPolicyInterval.
+	FsyncInterval time.Duration
+}
+
+// WAL is a segmented, size-rotated write-ahead log of TraceEvents. Host
+// appends every event to the WAL before submitting it to the configured
+// DataStream, and only acks (and eventually reaps) the entry once the
+// DataStream confirms delivery - so a crash or a flaky sink can't silently
+// drop research data.
+type WAL struct {
+	dir         string
+	maxBytes    int64
+	fsyncPolicy FsyncPolicy
+
+	mu          sync.Mutex
+	file        *os.File
+	segmentPath string
+	writtenSize int64
+
+	// total tracks, per segment path, how many entries have been appended.
+	// ackedPrefix is the longest contiguous run of acked entries starting
+	// from index 0 - the durable "first N lines are done" offset written to
+	// ackMarkerPath/readAckOffset. ackedPending holds indexes acked out of
+	// order (RawTracer hooks fire concurrently from go-libp2p-pubsub's
+	// validation workers, so Acks can complete out of append order) until
+	// they join up with ackedPrefix.
+	total        map[string]int
+	ackedPrefix  map[string]int
+	ackedPending map[string]map[int]bool
+
+	stopFsync chan struct{}
+	fsyncWG   sync.WaitGroup
+}
+
+// walEntryHandle identifies an Append'd entry so the caller can Ack it once
+// the DataStream has confirmed delivery. index is the entry's 0-based
+// position within segment, needed because Acks can arrive out of append
+// order.
+type walEntryHandle struct {
+	segment string
+	index   int
+}
+
+// NewWAL opens (or creates) a WAL rooted at cfg.Dir.
+func NewWAL(cfg WALConfig) (*WAL, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("wal requires a directory")
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create wal dir: %w", err)
+	}
+
+	maxBytes := cfg.MaxSegmentBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultWALMaxSegmentBytes
+	}
+
+	policy := cfg.FsyncPolicy
+	if policy == "" {
+		policy = FsyncPolicyInterval
+	}
+
+	w := &WAL{
+		dir:          cfg.Dir,
+		maxBytes:     maxBytes,
+		fsyncPolicy:  policy,
+		total:        make(map[string]int),
+		ackedPrefix:  make(map[string]int),
+		ackedPending: make(map[string]map[int]bool),
+	}
+
+	if err := w.rotate(); err != nil {
+		return nil, err
+	}
+
+	if policy == FsyncPolicyInterval {
+		interval := cfg.FsyncInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		w.stopFsync = make(chan struct{})
+		w.fsyncWG.Add(1)
+		go w.fsyncLoop(interval)
+	}
+
+	return w, nil
+}
+
+func (w *WAL) fsyncLoop(interval time.Duration) {
+	defer w.fsyncWG.Done()
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			w.mu.Lock()
+			if w.file != nil {
+				_ = w.file.Sync()
+			}
+			w.mu.Unlock()
+		case <-w.stopFsync:
+			return
+		}
+	}
+}
+
+// Append serializes evt as a JSON line into the active segment and returns a
+// handle to Ack once the event has been durably delivered downstream.
+func (w *WAL) Append(evt *TraceEvent) (walEntryHandle, error) {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return walEntryHandle{}, fmt.Errorf("marshal trace event: %w", err)
+	}
+	data = append(data, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.writtenSize+int64(len(data)) > w.maxBytes {
+		if err := w.rotateLocked(); err != nil {
+			return walEntryHandle{}, err
+		}
+	}
+
+	if _, err := w.file.Write(data); err != nil {
+		return walEntryHandle{}, fmt.Errorf("write wal entry: %w", err)
+	}
+	w.writtenSize += int64(len(data))
+	index := w.total[w.segmentPath]
+	w.total[w.segmentPath] = index + 1
+
+	if w.fsyncPolicy == FsyncPolicyAlways {
+		if err := w.file.Sync(); err != nil {
+			return walEntryHandle{}, fmt.Errorf("fsync wal entry: %w", err)
+		}
+	}
+
+	return walEntryHandle{segment: w.segmentPath, index: index}, nil
+}
+
+// Ack marks a previously Append'd entry as durably delivered and advances
+// that segment's durable ack offset. go-libp2p-pubsub invokes RawTracer
+// hooks concurrently from its validation worker pool, so Acks can complete
+// out of append order; Ack only advances the durable offset once it has
+// every index in the contiguous run starting at 0, so a line is never
+// marked acked before every line ahead of it is also confirmed. Once every
+// entry in a rotated-away segment has been acked, the segment (and its ack
+// marker) is deleted. The still-active segment is never deleted here - it's
+// still being appended to - but its ack offset is kept current so a clean
+// Close, or a replay after a crash, doesn't re-ship entries that already
+// made it out.
+func (w *WAL) Ack(h walEntryHandle) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pending := w.ackedPending[h.segment]
+	if pending == nil {
+		pending = make(map[int]bool)
+		w.ackedPending[h.segment] = pending
+	}
+	pending[h.index] = true
+
+	prefix := w.ackedPrefix[h.segment]
+	for pending[prefix] {
+		delete(pending, prefix)
+		prefix++
+	}
+
+	if prefix == w.ackedPrefix[h.segment] {
+		return
+	}
+	w.ackedPrefix[h.segment] = prefix
+
+	if err := writeAckOffset(h.segment, prefix); err != nil {
+		// Best effort: a stale ack marker only costs a few re-shipped
+		// events on the next replay, never data loss.
+		return
+	}
+
+	total := w.total[h.segment]
+	if prefix < total {
+		return
+	}
+
+	if h.segment == w.segmentPath {
+		// Fully acked, but still the active segment - Append may still
+		// write more to it, so there's nothing to reap yet, and its
+		// counters are kept so later entries continue its index sequence.
+		return
+	}
+
+	delete(w.total, h.segment)
+	delete(w.ackedPrefix, h.segment)
+	delete(w.ackedPending, h.segment)
+
+	if err := os.Remove(h.segment); err != nil && !os.IsNotExist(err) {
+		// Leave it for the next Close/restart; replay's ack offset means
+		// re-attempting this cleanup later is safe.
+		return
+	}
+	_ = os.Remove(ackMarkerPath(h.segment))
+}
+
+func (w *WAL) rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotateLocked()
+}
+
+func (w *WAL) rotateLocked() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("close previous wal segment: %w", err)
+		}
+	}
+
+	name := fmt.Sprintf("wal-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000"))
+	path := filepath.Join(w.dir, name)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create wal segment: %w", err)
+	}
+
+	w.file = f
+	w.segmentPath = path
+	w.writtenSize = 0
+
+	return nil
+}
+
+// Close flushes and closes the active segment and stops the fsync loop, if
+// any. If every entry written to the active segment has already been acked,
+// Close deletes it (and its ack marker) so a subsequent clean restart's
+// ReplayWAL has nothing left to re-ship; otherwise it's left in place,
+// ack offset and all, for Replay to pick up from where it left off.
+func (w *WAL) Close() error {
+	if w.stopFsync != nil {
+		close(w.stopFsync)
+		w.fsyncWG.Wait()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	segment := w.segmentPath
+	fullyAcked := w.ackedPrefix[segment] >= w.total[segment]
+
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if fullyAcked {
+		_ = os.Remove(segment)
+		_ = os.Remove(ackMarkerPath(segment))
+	}
+
+	return nil
+}
+
+// ackMarkerPath returns the sidecar path durably tracking segment's ack
+// offset (see writeAckOffset/readAckOffset).
+func ackMarkerPath(segment string) string {
+	return segment + walAckMarkerSuffix
+}
+
+// writeAckOffset durably records that the first n lines of segment have
+// been acked. It's written on every Ack rather than batched, since losing
+// this file just means Replay over-ships a handful of already-delivered
+// events, not that it silently drops any.
+func writeAckOffset(segment string, n int) error {
+	return os.WriteFile(ackMarkerPath(segment), []byte(strconv.Itoa(n)), 0o644)
+}
+
+// readAckOffset reads segment's durable ack offset, returning 0 if no
+// marker exists (nothing acked yet) or it can't be parsed.
+func readAckOffset(segment string) int {
+	data, err := os.ReadFile(ackMarkerPath(segment))
+	if err != nil {
+		return 0
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || n < 0 {
+		return 0
+	}
+
+	return n
+}
+
+// Replay reads every segment file under dir (in rotation order) and submits
+// each unacked TraceEvent to ds. It's the counterpart to the at-least-once
+// delivery WAL provides: lines beyond each segment's durable ack offset
+// (see writeAckOffset) are exactly the ones that were never confirmed
+// delivered, whether that's because of a crash, a sink outage, or the
+// segment still being active when the process stopped. Used by
+// `hermes replay <wal-dir>`.
+func Replay(ctx context.Context, dir string, ds DataStream) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read wal dir: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), walAckMarkerSuffix) {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := replaySegment(ctx, filepath.Join(dir, name), ds); err != nil {
+			return fmt.Errorf("replay segment %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func replaySegment(ctx context.Context, path string, ds DataStream) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	skip := readAckOffset(path)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line <= skip {
+			continue
+		}
+
+		var evt TraceEvent
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := ds.PutEvent(ctx, &evt); err != nil {
+			return fmt.Errorf("line %d: put event: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,23 @@
+package host
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReplayWAL re-submits any segments left over in the host's own WAL
+// directory (i.e. events that were never acked, typically left behind by an
+// unclean shutdown) through the currently configured DataStream. Host
+// construction should call this once, before serving any traffic, whenever
+// a WAL is configured.
+func (h *Host) ReplayWAL(ctx context.Context) error {
+	if h.wal == nil {
+		return nil
+	}
+
+	if err := Replay(ctx, h.wal.dir, h.cfg.DataStream); err != nil {
+		return fmt.Errorf("replay wal: %w", err)
+	}
+
+	return nil
+}
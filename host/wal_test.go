@@ -0,0 +1,241 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+const testWALPeerID = "12D3KooWAJjbRkp8FPF5i51QHT3Gn6aUbwgVKkGAT9wWzgoTLK7V"
+
+func newTestWALEvent(t *testing.T, evtType EventType) *TraceEvent {
+	t.Helper()
+
+	id, err := peer.Decode(testWALPeerID)
+	if err != nil {
+		t.Fatalf("decode test peer id: %v", err)
+	}
+
+	return &TraceEvent{
+		Version:   TraceEventSchemaVersion,
+		NetworkID: "test",
+		HostID:    id.String(),
+		Type:      evtType,
+		PeerID:    id,
+		Timestamp: time.Unix(0, 0),
+		Payload:   map[string]any{"Topic": "test-topic"},
+	}
+}
+
+func newTestWAL(t *testing.T) *WAL {
+	t.Helper()
+
+	w, err := NewWAL(WALConfig{Dir: t.TempDir(), FsyncPolicy: FsyncPolicyNever})
+	if err != nil {
+		t.Fatalf("NewWAL: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+
+	return w
+}
+
+func TestWALAckReapsRotatedSegment(t *testing.T) {
+	w := newTestWAL(t)
+
+	h, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	oldSegment := h.segment
+
+	if err := w.rotate(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	w.Ack(h)
+
+	if _, err := os.Stat(oldSegment); !os.IsNotExist(err) {
+		t.Fatalf("expected rotated-away, fully-acked segment %s to be removed, stat err = %v", oldSegment, err)
+	}
+	if _, err := os.Stat(ackMarkerPath(oldSegment)); !os.IsNotExist(err) {
+		t.Fatalf("expected ack marker for %s to be removed alongside the segment", oldSegment)
+	}
+}
+
+func TestWALAckDoesNotReapActiveSegment(t *testing.T) {
+	w := newTestWAL(t)
+
+	h, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	w.Ack(h)
+
+	if _, err := os.Stat(h.segment); err != nil {
+		t.Fatalf("expected active segment to remain on disk after Ack, got stat err = %v", err)
+	}
+	if got := readAckOffset(h.segment); got != 1 {
+		t.Fatalf("readAckOffset(%s) = %d, want 1", h.segment, got)
+	}
+}
+
+func TestWALCloseRemovesFullyAckedActiveSegment(t *testing.T) {
+	w := newTestWAL(t)
+
+	h, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	segment := h.segment
+
+	w.Ack(h)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(segment); !os.IsNotExist(err) {
+		t.Fatalf("expected a clean Close with nothing outstanding to remove %s, stat err = %v", segment, err)
+	}
+}
+
+func TestWALCloseKeepsUnackedActiveSegment(t *testing.T) {
+	w := newTestWAL(t)
+
+	h, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	segment := h.segment
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(segment); err != nil {
+		t.Fatalf("expected unacked active segment %s to survive Close, stat err = %v", segment, err)
+	}
+}
+
+func TestWALAckOutOfOrderDoesNotAdvancePastUnackedEntry(t *testing.T) {
+	w := newTestWAL(t)
+
+	h1, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append 1: %v", err)
+	}
+	h2, err := w.Append(newTestWALEvent(t, EventTypeLeave))
+	if err != nil {
+		t.Fatalf("Append 2: %v", err)
+	}
+
+	// The second entry's Ack lands first, as can happen since
+	// go-libp2p-pubsub's RawTracer hooks fire concurrently from its
+	// validation worker pool. The durable offset must not advance past the
+	// still-unacked first entry.
+	w.Ack(h2)
+	if got := readAckOffset(h1.segment); got != 0 {
+		t.Fatalf("readAckOffset(%s) = %d after only the second entry acked, want 0", h1.segment, got)
+	}
+
+	w.Ack(h1)
+	if got := readAckOffset(h1.segment); got != 2 {
+		t.Fatalf("readAckOffset(%s) = %d after both entries acked, want 2", h1.segment, got)
+	}
+}
+
+func TestReplaySkipsAckedEntriesInActiveSegment(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWAL(WALConfig{Dir: dir, FsyncPolicy: FsyncPolicyNever})
+	if err != nil {
+		t.Fatalf("NewWAL: %v", err)
+	}
+
+	h1, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append 1: %v", err)
+	}
+	if _, err := w.Append(newTestWALEvent(t, EventTypeLeave)); err != nil {
+		t.Fatalf("Append 2: %v", err)
+	}
+
+	// Only the first entry is acked, simulating a crash after the first
+	// event made it to the sink but before the second did.
+	w.Ack(h1)
+
+	if err := w.file.Close(); err != nil {
+		t.Fatalf("close underlying file: %v", err)
+	}
+
+	ds := &recordingDataStream{}
+	if err := Replay(context.Background(), dir, ds); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+
+	if len(ds.events) != 1 {
+		t.Fatalf("got %d replayed events, want 1 (the unacked one)", len(ds.events))
+	}
+	if ds.events[0].Type != EventTypeLeave {
+		t.Fatalf("replayed event type = %s, want %s", ds.events[0].Type, EventTypeLeave)
+	}
+}
+
+func TestReplayIgnoresAckMarkerFiles(t *testing.T) {
+	dir := t.TempDir()
+	w, err := NewWAL(WALConfig{Dir: dir, FsyncPolicy: FsyncPolicyNever})
+	if err != nil {
+		t.Fatalf("NewWAL: %v", err)
+	}
+
+	h, err := w.Append(newTestWALEvent(t, EventTypeJoin))
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	w.Ack(h)
+
+	if err := w.file.Close(); err != nil {
+		t.Fatalf("close underlying file: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	foundMarker := false
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == walAckMarkerSuffix {
+			foundMarker = true
+		}
+	}
+	if !foundMarker {
+		t.Fatal("expected an ack marker file to exist alongside the active segment")
+	}
+
+	ds := &recordingDataStream{}
+	if err := Replay(context.Background(), dir, ds); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(ds.events) != 0 {
+		t.Fatalf("got %d replayed events, want 0 (fully acked)", len(ds.events))
+	}
+}
+
+// recordingDataStream is a minimal DataStream test double that just records
+// every event handed to PutEvent.
+type recordingDataStream struct {
+	events []*TraceEvent
+}
+
+func (ds *recordingDataStream) PutEvent(_ context.Context, evt *TraceEvent) error {
+	ds.events = append(ds.events, evt)
+	return nil
+}
+
+func (ds *recordingDataStream) Type() string { return "recording" }
+
+func (ds *recordingDataStream) Close() error { return nil }